@@ -0,0 +1,70 @@
+package main
+
+import (
+	"errors"
+
+	"github.com/denysvitali/git-cc/pkg/git"
+	"github.com/denysvitali/git-cc/pkg/message"
+	"github.com/denysvitali/git-cc/ui"
+)
+
+// Exit codes of git-cc, documented in the README so that scripts and hooks
+// can tell why a run failed.
+const (
+	exitOK = 0
+	// exitInternal covers every failure without a code of its own.
+	exitInternal = 1
+	// exitUsage is also used by the flag package for unknown flags.
+	exitUsage         = 2
+	exitNotRepository = 3
+	exitNothingStaged = 4
+	exitHookFailed    = 5
+	exitLintFailed    = 6
+	exitAborted       = 7
+	exitConflict      = 8
+)
+
+// exitCode returns the exit code of a run that ended with out and err.
+func exitCode(out outcome, err error) int {
+	var lintErr *message.LintError
+	switch {
+	case errors.As(err, &lintErr):
+		return exitLintFailed
+	case errors.Is(err, ui.ErrAborted):
+		return exitAborted
+	case errors.Is(err, ui.ErrConflicts):
+		return exitConflict
+	case errors.Is(err, ui.ErrNothingStaged):
+		return exitNothingStaged
+	case err != nil:
+		return exitInternal
+	case out.conflicted:
+		return exitConflict
+	case out.result != nil && !out.result.Success:
+		return errorTypeExitCode(out.result.Type)
+	case out.result == nil && out.message == "":
+		// The user quit before confirming the message
+		return exitAborted
+	}
+	return exitOK
+}
+
+// errorTypeExitCode returns the exit code of a commit that failed with
+// errType.
+func errorTypeExitCode(errType git.ErrorType) int {
+	switch errType {
+	case git.ErrorTypeNotInRepo:
+		return exitNotRepository
+	case git.ErrorTypeNoChanges:
+		return exitNothingStaged
+	case git.ErrorTypeHookFailed, git.ErrorTypeCommitMsgHookFailed, git.ErrorTypeTimeout:
+		return exitHookFailed
+	case git.ErrorTypeEmptyMessage:
+		return exitLintFailed
+	case git.ErrorTypeCancelled:
+		return exitAborted
+	case git.ErrorTypeMergeConflict, git.ErrorTypeOperationInProgress:
+		return exitConflict
+	}
+	return exitInternal
+}
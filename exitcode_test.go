@@ -0,0 +1,45 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/denysvitali/git-cc/pkg/conventional"
+	"github.com/denysvitali/git-cc/pkg/git"
+	"github.com/denysvitali/git-cc/pkg/message"
+	"github.com/denysvitali/git-cc/ui"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name     string
+		out      outcome
+		err      error
+		expected int
+	}{
+		{"committed", outcome{message: "feat: a", result: &git.CommitResult{Success: true}}, nil, exitOK},
+		{"dry run", outcome{message: "feat: a"}, nil, exitOK},
+		{"quit", outcome{}, nil, exitAborted},
+		{"conflicts", outcome{conflicted: true}, nil, exitConflict},
+		{"lint", outcome{}, message.Lint(conventional.Message{Type: "feature", Subject: "a"}), exitLintFailed},
+		{"input ended", outcome{}, ui.ErrAborted, exitAborted},
+		{"plain conflicts", outcome{}, fmt.Errorf("a merge is in progress with %w", ui.ErrConflicts), exitConflict},
+		{"nothing staged", outcome{}, fmt.Errorf("%w, stage the changes", ui.ErrNothingStaged), exitNothingStaged},
+		{"other error", outcome{}, errors.New("failed"), exitInternal},
+		{"hook", outcome{message: "feat: a", result: &git.CommitResult{Type: git.ErrorTypeHookFailed}}, nil, exitHookFailed},
+		{"commit-msg hook", outcome{message: "feat: a", result: &git.CommitResult{Type: git.ErrorTypeCommitMsgHookFailed}},
+			nil, exitHookFailed},
+		{"cancelled", outcome{message: "feat: a", result: &git.CommitResult{Type: git.ErrorTypeCancelled}}, nil, exitAborted},
+		{"no changes", outcome{message: "feat: a", result: &git.CommitResult{Type: git.ErrorTypeNoChanges}},
+			nil, exitNothingStaged},
+		{"identity", outcome{message: "feat: a", result: &git.CommitResult{Type: git.ErrorTypeIdentityMissing}},
+			nil, exitInternal},
+	}
+
+	for _, tt := range tests {
+		if code := exitCode(tt.out, tt.err); code != tt.expected {
+			t.Errorf("%s: expected exit code %d, got %d", tt.name, tt.expected, code)
+		}
+	}
+}
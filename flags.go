@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/denysvitali/git-cc/pkg/config"
+	"github.com/denysvitali/git-cc/pkg/conventional"
+	"github.com/denysvitali/git-cc/pkg/git"
+	"github.com/denysvitali/git-cc/pkg/history"
+	"github.com/denysvitali/git-cc/pkg/message"
+	"github.com/denysvitali/git-cc/ui"
+)
+
+// footerFlag collects the repeatable --footer flag.
+type footerFlag []conventional.Footer
+
+func (f *footerFlag) String() string {
+	footers := make([]string, len(*f))
+	for i, footer := range *f {
+		footers[i] = footer.String()
+	}
+	return strings.Join(footers, ", ")
+}
+
+// Set adds a footer given as key=value.
+func (f *footerFlag) Set(value string) error {
+	token, text, ok := strings.Cut(value, "=")
+	if !ok {
+		return fmt.Errorf("expected key=value, got %q", value)
+	}
+	footer, err := conventional.NewFooter(token, text)
+	if err != nil {
+		return err
+	}
+	*f = append(*f, footer)
+	return nil
+}
+
+// messageFlags build the message from the command line instead of the
+// interface.
+type messageFlags struct {
+	commitType string
+	scope      string
+	subject    string
+	body       string
+	footers    footerFlag
+	breaking   bool
+}
+
+func (f *messageFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.commitType, "type", "", "Commit `type`, such as feat or fix, without starting the interface")
+	fs.StringVar(&f.scope, "scope", "", "Commit `scope`")
+	fs.StringVar(&f.subject, "subject", "", "Commit `subject`")
+	fs.StringVar(&f.body, "body", "", "Commit `body`")
+	fs.Var(&f.footers, "footer", "Add a footer given as `key=value`, such as Refs=#12 (repeatable)")
+	fs.BoolVar(&f.breaking, "breaking", false, "Mark the commit as a breaking change")
+}
+
+// given reports whether any of the message flags was used.
+func (f *messageFlags) given() bool {
+	return f.commitType != "" || f.scope != "" || f.subject != "" || f.body != "" ||
+		len(f.footers) > 0 || f.breaking
+}
+
+func (f *messageFlags) fields() conventional.Message {
+	return conventional.Message{
+		Type:     f.commitType,
+		Scope:    f.scope,
+		Subject:  f.subject,
+		Breaking: f.breaking,
+		Body:     f.body,
+		Footers:  f.footers,
+	}
+}
+
+// commitFromFlags lints and commits the message built from flags without
+// starting the interface. In dry-run mode it only builds the message.
+func commitFromFlags(fields conventional.Message, opts ui.Options) (outcome, error) {
+	if err := message.Lint(fields); err != nil {
+		return outcome{}, err
+	}
+	out := outcome{message: fields.String()}
+	if opts.DryRun {
+		return out, nil
+	}
+
+	// A merge can be committed even if it changes nothing
+	stagedFiles, err := git.GetStagedFiles()
+	if err != nil {
+		return outcome{}, fmt.Errorf("checking git status: %w", err)
+	}
+	if len(stagedFiles) == 0 && git.OperationInProgress() != "merge" {
+		return outcome{}, fmt.Errorf("%w, stage the changes to commit with git add", ui.ErrNothingStaged)
+	}
+
+	if branch, err := git.CurrentBranch(); err == nil && config.IsProtectedBranch(opts.ProtectedBranches, branch) {
+		if opts.BlockProtectedBranches {
+			return outcome{}, fmt.Errorf("%s is a protected branch, commit on another branch", branch)
+		}
+		fmt.Fprintf(os.Stderr, "Warning: committing to the protected branch %s\n", branch)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	// Only the output of hooks is shown, as run prints its own summary
+	out.result = git.CommitWithResult(ctx, out.message, git.CommitOptions{
+		Args:    append([]string{"--quiet"}, opts.CommitArgs...),
+		Output:  os.Stderr,
+		Timeout: opts.Timeout,
+	})
+	if !out.result.Success {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", out.result.Message)
+		if out.result.Hint != "" {
+			fmt.Fprintf(os.Stderr, "Hint: %s\n", out.result.Hint)
+		}
+		return out, nil
+	}
+
+	if opts.HistorySize > 0 {
+		_ = history.Add(history.NewEntry(out.message), opts.HistorySize)
+	}
+	return out, nil
+}
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -79,17 +81,60 @@ func TestApplicationNoStagedFiles(t *testing.T) {
 		t.Fatalf("Failed to init git repo: %v", err)
 	}
 
-	cmd := exec.Command("go", "run", ".")
-	cmd.Dir = originalDir
-	cmd.Env = append(os.Environ(), "GIT_CC_TEST_DIR="+tempDir)
+	cmd := exec.Command(buildBinary(t, originalDir))
+	cmd.Dir = tempDir
+
+	output, err := cmd.CombinedOutput()
+	if err == nil {
+		t.Error("Expected application to fail with no staged files")
+	}
+
+	if !strings.Contains(string(output), "no staged files found") {
+		t.Errorf("Expected 'no staged files found' error, got: %s", string(output))
+	}
+	if code := cmd.ProcessState.ExitCode(); code != exitNothingStaged {
+		t.Errorf("Expected exit code %d, got %d", exitNothingStaged, code)
+	}
+}
+
+func TestApplicationDirFlag(t *testing.T) {
+	repoDir := t.TempDir()
+	if err := exec.Command("git", "init", repoDir).Run(); err != nil {
+		t.Fatalf("Failed to init git repo: %v", err)
+	}
 
+	originalDir, _ := os.Getwd()
+	binary := buildBinary(t, originalDir)
+
+	// Run from a directory outside the repository
+	cmd := exec.Command(binary, "-C", repoDir)
+	cmd.Dir = t.TempDir()
 	output, err := cmd.CombinedOutput()
 	if err == nil {
 		t.Error("Expected application to fail with no staged files")
 	}
+	if !strings.Contains(string(output), "no staged files found") {
+		t.Errorf("Expected 'no staged files found' error, got: %s", string(output))
+	}
 
-	if !strings.Contains(string(output), "No staged files found") {
-		t.Errorf("Expected 'No staged files found' error, got: %s", string(output))
+	cmd = exec.Command(binary, "-C", filepath.Join(repoDir, "missing"))
+	output, err = cmd.CombinedOutput()
+	if err == nil || !strings.Contains(string(output), "cannot change to") {
+		t.Errorf("Expected error for a missing directory, got: %s", string(output))
+	}
+	if code := cmd.ProcessState.ExitCode(); code != exitUsage {
+		t.Errorf("Expected exit code %d, got %d", exitUsage, code)
+	}
+
+	outside := t.TempDir()
+	cmd = exec.Command(binary, "-C", outside)
+	cmd.Env = append(os.Environ(), "GIT_CEILING_DIRECTORIES="+filepath.Dir(outside))
+	output, _ = cmd.CombinedOutput()
+	if !strings.Contains(string(output), "not a git repository") {
+		t.Errorf("Expected 'not a git repository' error, got: %s", string(output))
+	}
+	if code := cmd.ProcessState.ExitCode(); code != exitNotRepository {
+		t.Errorf("Expected exit code %d, got %d", exitNotRepository, code)
 	}
 }
 
@@ -136,7 +181,7 @@ exit 1
 	}
 
 	// Test git package commit function with failing hook
-	result := git.CommitWithResult("feat: test commit with failing hook")
+	result := git.CommitWithResult(context.Background(), "feat: test commit with failing hook", git.CommitOptions{})
 	if result.Success {
 		t.Error("Expected commit to fail due to pre-commit hook")
 	}
@@ -197,7 +242,7 @@ func TestConventionalCommitMessageFormat(t *testing.T) {
 				t.Fatalf("Failed to stage file: %v", err)
 			}
 
-			result := git.CommitWithResult(tc.message)
+			result := git.CommitWithResult(context.Background(), tc.message, git.CommitOptions{})
 
 			if tc.valid && !result.Success {
 				t.Errorf("Expected commit to succeed for valid message: %s", tc.message)
@@ -216,6 +261,19 @@ func TestConventionalCommitMessageFormat(t *testing.T) {
 	}
 }
 
+// Helper function to build the application binary
+func buildBinary(t *testing.T, sourceDir string) string {
+	t.Helper()
+
+	binary := filepath.Join(t.TempDir(), "git-cc")
+	cmd := exec.Command("go", "build", "-o", binary, ".")
+	cmd.Dir = sourceDir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("Failed to build application: %v\n%s", err, output)
+	}
+	return binary
+}
+
 // Helper function to run commands
 func runCommand(args ...string) error {
 	cmd := exec.Command(args[0], args[1:]...)
@@ -230,3 +288,193 @@ func runCommandWithOutput(args ...string) (string, error) {
 	output, err := cmd.Output()
 	return strings.TrimSpace(string(output)), err
 }
+
+func TestApplicationMessageFlags(t *testing.T) {
+	repoDir := t.TempDir()
+	commands := [][]string{
+		{"git", "init", "-q", repoDir},
+		{"git", "-C", repoDir, "config", "user.name", "Test User"},
+		{"git", "-C", repoDir, "config", "user.email", "test@example.com"},
+	}
+	for _, args := range commands {
+		if err := runCommand(args...); err != nil {
+			t.Fatalf("Failed to run command %v: %v", args, err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(repoDir, "a.txt"), []byte("a"), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	if err := runCommand("git", "-C", repoDir, "add", "a.txt"); err != nil {
+		t.Fatalf("Failed to stage file: %v", err)
+	}
+
+	originalDir, _ := os.Getwd()
+	binary := buildBinary(t, originalDir)
+
+	cmd := exec.Command(binary, "-C", repoDir, "--type", "feature", "--subject", "add a")
+	output, err := cmd.CombinedOutput()
+	if err == nil || !strings.Contains(string(output), `unknown type "feature"`) {
+		t.Errorf("Expected a lint error, got: %v, %s", err, output)
+	}
+	if code := cmd.ProcessState.ExitCode(); code != exitLintFailed {
+		t.Errorf("Expected exit code %d, got %d", exitLintFailed, code)
+	}
+
+	cmd = exec.Command(binary, "-C", repoDir, "--type", "feat", "--scope", "io", "--subject", "add a",
+		"--body", "Adds a.", "--footer", "Refs=#1")
+	output, err = cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("Expected the commit to succeed, got: %v, %s", err, output)
+	}
+	if !strings.Contains(string(output), "] feat(io): add a (1 file changed, +1 -0)") {
+		t.Errorf("Expected the commit to be reported, got: %s", output)
+	}
+
+	log, err := runCommandWithOutput("git", "-C", repoDir, "log", "-1", "--format=%B")
+	if err != nil || log != "feat(io): add a\n\nAdds a.\n\nRefs: #1" {
+		t.Errorf("Unexpected commit message %q, %v", log, err)
+	}
+
+	// Unstaged changes are not committed
+	if err := os.WriteFile(filepath.Join(repoDir, "a.txt"), []byte("b"), 0644); err != nil {
+		t.Fatalf("Failed to change file: %v", err)
+	}
+	cmd = exec.Command(binary, "-C", repoDir, "--type", "fix", "--subject", "change a")
+	output, _ = cmd.CombinedOutput()
+	if !strings.Contains(string(output), "nothing is staged") {
+		t.Errorf("Expected an error about nothing being staged, got: %s", output)
+	}
+	if code := cmd.ProcessState.ExitCode(); code != exitNothingStaged {
+		t.Errorf("Expected exit code %d, got %d", exitNothingStaged, code)
+	}
+}
+
+func TestApplicationLog(t *testing.T) {
+	repoDir := t.TempDir()
+	commands := [][]string{
+		{"git", "init", "-q", repoDir},
+		{"git", "-C", repoDir, "config", "user.name", "Test User"},
+		{"git", "-C", repoDir, "config", "user.email", "test@example.com"},
+		{"git", "-C", repoDir, "commit", "-q", "--allow-empty", "-m", "feat(io): add a"},
+		{"git", "-C", repoDir, "commit", "-q", "--allow-empty", "-m", "Update readme"},
+		{"git", "-C", repoDir, "commit", "-q", "--allow-empty", "-m", "fix!: drop -x"},
+	}
+	for _, args := range commands {
+		if err := runCommand(args...); err != nil {
+			t.Fatalf("Failed to run command %v: %v", args, err)
+		}
+	}
+
+	originalDir, _ := os.Getwd()
+	binary := buildBinary(t, originalDir)
+
+	// Without a terminal the matching commits are printed
+	output, err := exec.Command(binary, "log", "-C", repoDir).CombinedOutput()
+	if err != nil {
+		t.Fatalf("Expected the log to succeed, got: %v, %s", err, output)
+	}
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	if len(lines) != 3 || !strings.Contains(lines[0], "fix!: drop -x") || !strings.Contains(lines[1], "✗ Update readme") {
+		t.Errorf("Expected the 3 commits, most recent first, got:\n%s", output)
+	}
+
+	output, err = exec.Command(binary, "log", "-C", repoDir, "type:feat").CombinedOutput()
+	if err != nil || !strings.Contains(string(output), "feat(io): add a") || strings.Count(string(output), "\n") != 1 {
+		t.Errorf("Expected the feat commit alone, got: %v, %s", err, output)
+	}
+
+	cmd := exec.Command(binary, "log", "-C", repoDir, "since:someday")
+	output, _ = cmd.CombinedOutput()
+	if code := cmd.ProcessState.ExitCode(); code != exitUsage || !strings.Contains(string(output), "invalid date") {
+		t.Errorf("Expected exit code %d for an invalid query, got %d: %s", exitUsage, code, output)
+	}
+}
+
+func TestApplicationDryRunPrompts(t *testing.T) {
+	repoDir := t.TempDir()
+	if err := runCommand("git", "init", "-q", repoDir); err != nil {
+		t.Fatalf("Failed to init git repo: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(repoDir, "a.txt"), []byte("a"), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	if err := runCommand("git", "-C", repoDir, "add", "a.txt"); err != nil {
+		t.Fatalf("Failed to stage file: %v", err)
+	}
+
+	originalDir, _ := os.Getwd()
+	binary := buildBinary(t, originalDir)
+
+	// The prompts go to stderr so that the captured stdout is the message
+	cmd := exec.Command(binary, "-C", repoDir, "--dry-run")
+	cmd.Stdin = strings.NewReader("feat\n\nadd thing\n.\n")
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("Expected the dry run to succeed, got: %v, %s", err, stderr.String())
+	}
+	if string(output) != "feat: add thing\n" {
+		t.Errorf("Expected the message alone on stdout, got %q", output)
+	}
+	if !strings.Contains(stderr.String(), "Subject: ") {
+		t.Errorf("Expected the prompts on stderr, got %q", stderr.String())
+	}
+
+	// Nothing is staged for a dry run, which does not offer to stage
+	if err := runCommand("git", "-C", repoDir, "reset", "-q"); err != nil {
+		t.Fatalf("Failed to unstage file: %v", err)
+	}
+	cmd = exec.Command(binary, "-C", repoDir, "--dry-run", "--ui", "tui")
+	output, _ = cmd.CombinedOutput()
+	if code := cmd.ProcessState.ExitCode(); code != exitNothingStaged || !strings.Contains(string(output), "nothing is staged") {
+		t.Errorf("Expected exit code %d, got %d: %s", exitNothingStaged, code, output)
+	}
+	if staged, err := runCommandWithOutput("git", "-C", repoDir, "diff", "--cached", "--name-only"); err != nil || staged != "" {
+		t.Errorf("Expected the index to be left alone, got %q, %v", staged, err)
+	}
+}
+
+func TestApplicationJSONFailures(t *testing.T) {
+	repoDir := t.TempDir()
+	if err := runCommand("git", "init", "-q", repoDir); err != nil {
+		t.Fatalf("Failed to init git repo: %v", err)
+	}
+
+	originalDir, _ := os.Getwd()
+	binary := buildBinary(t, originalDir)
+
+	// runJSON returns the decoded stdout and the exit code of a run
+	runJSON := func(args ...string) (map[string]any, int) {
+		t.Helper()
+		cmd := exec.Command(binary, append([]string{"-C", repoDir, "--output", "json"}, args...)...)
+		output, _ := cmd.Output()
+		var decoded map[string]any
+		if err := json.Unmarshal(output, &decoded); err != nil {
+			t.Fatalf("Expected JSON on stdout, got %q: %v", output, err)
+		}
+		return decoded, cmd.ProcessState.ExitCode()
+	}
+
+	decoded, code := runJSON()
+	jsonErr, _ := decoded["error"].(map[string]any)
+	if decoded["success"] != false || jsonErr["type"] != "no_changes" || code != exitNothingStaged {
+		t.Errorf("Expected nothing to commit with exit code %d, got %d: %v", exitNothingStaged, code, decoded)
+	}
+
+	if err := os.WriteFile(filepath.Join(repoDir, "a.txt"), []byte("a"), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	if err := runCommand("git", "-C", repoDir, "add", "a.txt"); err != nil {
+		t.Fatalf("Failed to stage file: %v", err)
+	}
+
+	decoded, code = runJSON("--type", "bogus", "--subject", "add a")
+	jsonErr, _ = decoded["error"].(map[string]any)
+	if decoded["success"] != false || jsonErr["type"] != "lint_failed" || code != exitLintFailed {
+		t.Errorf("Expected a lint failure with exit code %d, got %d: %v", exitLintFailed, code, decoded)
+	}
+	if message, _ := jsonErr["message"].(string); !strings.Contains(message, `unknown type "bogus"`) {
+		t.Errorf("Expected the lint error as the message, got %v", jsonErr)
+	}
+}
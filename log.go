@@ -0,0 +1,69 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/denysvitali/git-cc/pkg/git"
+	"github.com/denysvitali/git-cc/ui"
+)
+
+// defaultLogLimit is how many commits git cc log lists without -n.
+const defaultLogLimit = 1000
+
+// runLog implements "git cc log", which browses the commits of the current
+// branch. The arguments are the initial filter query. Without a terminal the
+// matching commits are printed instead.
+func runLog(args []string) int {
+	fs := flag.NewFlagSet("git cc log", flag.ContinueOnError)
+	var repoPath string
+	var limit int
+	fs.StringVar(&repoPath, "C", "", "Run as if git-cc was started in `path` instead of the current directory")
+	fs.IntVar(&limit, "n", defaultLogLimit, "List at most `count` commits, or all of them with 0")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: git cc log [flags] [query...]\n\n"+
+			"The query filters the commits, such as\n"+
+			"  type:feat,fix scope:ui author:ann since:2024-01-01 until:2024-06-30 words\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+
+	if err := git.SetDir(repoPath); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return exitUsage
+	}
+	if !git.IsGitRepository() {
+		fmt.Println("Error: not a git repository (or any of the parent directories): .git")
+		return exitNotRepository
+	}
+
+	entries, err := git.Log(limit)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return exitInternal
+	}
+	model, err := ui.NewLogModel(entries, strings.Join(fs.Args(), " "))
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return exitUsage
+	}
+
+	if plain, _ := usePlainUI("auto"); plain {
+		fmt.Print(model.PlainView())
+		return exitOK
+	}
+	if _, err := tea.NewProgram(model, tea.WithAltScreen()).Run(); err != nil {
+		fmt.Printf("Error: failed to run the interface: %v\n", err)
+		return exitInternal
+	}
+	return exitOK
+}
@@ -1,12 +1,17 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"os"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 
+	"github.com/denysvitali/git-cc/pkg/config"
 	"github.com/denysvitali/git-cc/pkg/git"
 	"github.com/denysvitali/git-cc/ui"
 )
@@ -19,39 +24,254 @@ var (
 )
 
 func main() {
-	var showVersion bool
+	os.Exit(run())
+}
+
+// run runs git-cc and returns its exit code.
+func run() int {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "parse":
+			return runParse(os.Args[2:], os.Stdin, os.Stdout, os.Stderr)
+		case "log":
+			return runLog(os.Args[2:])
+		}
+	}
+
+	var showVersion, dryRun bool
+	var outputFile, repoPath, uiMode, outputFormat string
+	var timeout time.Duration
+	var msgFlags messageFlags
 	flag.BoolVar(&showVersion, "version", false, "Show version information")
+	flag.StringVar(&repoPath, "C", "", "Run as if git-cc was started in `path` instead of the current directory")
+	flag.BoolVar(&dryRun, "dry-run", false, "Print the commit message to stdout instead of committing")
+	flag.StringVar(&outputFile, "output-file", "", "Write the commit message to `path` instead of committing")
+	flag.DurationVar(&timeout, "timeout", 0, "Abort the commit if it and its hooks run longer than `duration` (overrides cc.timeout)")
+	flag.StringVar(&uiMode, "ui", "auto", "Interface `mode`: tui, plain for line-based prompts, or auto (plain without a terminal)")
+	flag.StringVar(&outputFormat, "output", "", "Print the result as `format` after the run; json is supported")
+	msgFlags.register(flag.CommandLine)
+	flag.Usage = usage
 	flag.Parse()
 
 	if showVersion {
 		printVersion()
-		return
+		return exitOK
+	}
+
+	if outputFormat != "" && outputFormat != "json" {
+		fmt.Fprintf(os.Stderr, "Error: invalid --output format %q, expected json\n", outputFormat)
+		return exitUsage
+	}
+	jsonOut := outputFormat == "json"
+
+	plain, err := usePlainUI(uiMode)
+	if err != nil {
+		return fail(jsonOut, exitUsage, err)
+	}
+
+	extraArgs, err := commitArgs(os.Args[1:], flag.Args())
+	if err != nil {
+		return fail(jsonOut, exitUsage, err)
+	}
+
+	if err := git.SetDir(repoPath); err != nil {
+		return fail(jsonOut, exitUsage, err)
 	}
 
 	// Check if we're in a git repository
 	if !git.IsGitRepository() {
-		fmt.Printf("Error: not a git repository (or any of the parent directories): .git")
-		os.Exit(1)
+		return fail(jsonOut, exitNotRepository, errors.New("not a git repository (or any of the parent directories): .git"))
 	}
 
 	// Check if there are staged files
 	stagedFiles, err := git.GetStagedFiles()
 	if err != nil {
-		fmt.Printf("Error checking git status: %v", err)
-		os.Exit(1)
+		return fail(jsonOut, exitInternal, fmt.Errorf("checking git status: %w", err))
 	}
 
+	stage := false
 	if len(stagedFiles) == 0 {
-		fmt.Printf("No staged files found. Stage files with 'git add' first.")
-		os.Exit(1)
+		changes, err := git.GetWorkingTreeStatus()
+		if err != nil {
+			return fail(jsonOut, exitInternal, fmt.Errorf("checking git status: %w", err))
+		}
+		// A merge can be committed even if it changes nothing
+		if len(changes) == 0 && git.OperationInProgress() != "merge" {
+			err := errors.New("no staged files found and the working tree is clean, nothing to commit")
+			return fail(jsonOut, exitNothingStaged, err)
+		}
+		stage = len(changes) > 0
+		// A dry run leaves the index alone, so it cannot offer to stage
+		if stage && (dryRun || outputFile != "") {
+			return fail(jsonOut, exitNothingStaged, errors.New("nothing is staged, stage the changes with git add first"))
+		}
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fail(jsonOut, exitInternal, fmt.Errorf("loading configuration: %w", err))
+	}
+
+	opts := ui.Options{
+		CommitArgs:    append(cfg.CommitArgs, extraArgs...),
+		DryRun:        dryRun || outputFile != "",
+		Stage:         stage,
+		AllowNoVerify: cfg.AllowNoVerify,
+		Timeout:       cfg.Timeout,
+		Draft:         !dryRun && outputFile == "",
+		HistorySize:   cfg.HistorySize,
+		Submodules:    !dryRun && outputFile == "",
+
+		ProtectedBranches:      cfg.ProtectedBranches,
+		BlockProtectedBranches: cfg.BlockProtectedBranches,
+		BlockDetachedHead:      cfg.BlockDetachedHead,
+		Summary:                true,
+		SummaryDelay:           cfg.SummaryDelay,
+	}
+	if timeout > 0 {
+		opts.Timeout = timeout
+	}
+
+	var out outcome
+	if msgFlags.given() {
+		out, err = commitFromFlags(msgFlags.fields(), opts)
+	} else {
+		// Keep stdout for the message or the JSON output when it is captured
+		prompts := os.Stdout
+		if jsonOut || dryRun || !isTerminal(os.Stdout) {
+			prompts = os.Stderr
+		}
+		out, err = runUI(opts, plain, prompts)
+	}
+	if err != nil {
+		return fail(jsonOut, exitCode(out, err), err)
+	}
+
+	if opts.DryRun && out.message != "" {
+		if err := writeMessage(out.message, dryRun && !jsonOut, outputFile); err != nil {
+			return fail(jsonOut, exitInternal, fmt.Errorf("writing commit message: %w", err))
+		}
 	}
 
-	p := tea.NewProgram(ui.InitialModel(), tea.WithAltScreen())
+	if jsonOut {
+		stagedFiles = stagedFilesAfter(out, stagedFiles)
+		if err := writeJSON(os.Stdout, newJSONOutput(out, opts.DryRun, stagedFiles)); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing JSON output: %v\n", err)
+			return exitInternal
+		}
+	} else {
+		report(out)
+	}
+	return exitCode(out, nil)
+}
+
+// fail reports err on stderr, and as JSON on stdout with --output json, and
+// returns code, the exit code of the run.
+func fail(jsonOut bool, code int, err error) int {
+	fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	if jsonOut {
+		if err := writeJSON(os.Stdout, newJSONFailure(code, err)); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing JSON output: %v\n", err)
+		}
+	}
+	return code
+}
+
+// report prints the new branch, the warnings of git and a one-line summary
+// after a successful commit.
+func report(out outcome) {
+	result := out.result
+	if result == nil || !result.Success {
+		return
+	}
+	if out.branch != "" {
+		fmt.Fprintf(os.Stderr, "Committed on the new branch %s\n", out.branch)
+	}
+	for _, warning := range result.Warnings {
+		fmt.Fprintln(os.Stderr, warning)
+	}
+	fmt.Println(summaryLine(result.SHA, out.message))
+}
+
+// usePlainUI reports whether to use line-based prompts instead of the full
+// interface for the --ui mode. In auto mode they are used when stdin or
+// stdout is not a terminal, or the terminal is dumb.
+func usePlainUI(mode string) (bool, error) {
+	switch mode {
+	case "tui":
+		return false, nil
+	case "plain":
+		return true, nil
+	case "auto":
+		return !isTerminal(os.Stdin) || !isTerminal(os.Stdout) || os.Getenv("TERM") == "dumb", nil
+	}
+	return false, fmt.Errorf("invalid --ui mode %q, expected auto, tui or plain", mode)
+}
 
-	if _, err := p.Run(); err != nil {
-		fmt.Printf("Error running program: %v", err)
-		os.Exit(1)
+// isTerminal reports whether f is a terminal.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// runUI asks for the message with the full interface, or with line-based
+// prompts if plain is set, and commits it. Both are written to prompts.
+func runUI(opts ui.Options, plain bool, prompts io.Writer) (outcome, error) {
+	var model ui.Model
+	if plain {
+		var err error
+		if model, err = ui.RunPlain(context.Background(), opts, os.Stdin, prompts); err != nil {
+			return outcome{}, err
+		}
+	} else {
+		finalModel, err := tea.NewProgram(ui.NewModel(opts), tea.WithAltScreen(), tea.WithOutput(prompts)).Run()
+		if err != nil {
+			return outcome{}, fmt.Errorf("failed to run the interface: %w", err)
+		}
+		model = finalModel.(ui.Model)
+	}
+
+	return outcome{
+		message:    model.CommitMessage(),
+		result:     model.GetCommitResult(),
+		branch:     model.CreatedBranch(),
+		conflicted: model.Conflicted(),
+	}, nil
+}
+
+// writeMessage prints the message to stdout when dryRun is set and writes it
+// to outputFile when one is given.
+func writeMessage(message string, dryRun bool, outputFile string) error {
+	if dryRun {
+		fmt.Println(message)
+	}
+	if outputFile != "" {
+		return os.WriteFile(outputFile, []byte(message+"\n"), 0o644)
+	}
+	return nil
+}
+
+func usage() {
+	fmt.Fprintf(flag.CommandLine.Output(), "Usage: git cc [flags] [-- <git commit args>...]\n"+
+		"       git cc parse [file]\n"+
+		"       git cc log [flags] [query...]\n\n"+
+		"Without --type and --subject, git-cc asks for the message interactively.\n\nFlags:\n")
+	flag.PrintDefaults()
+}
+
+// commitArgs returns the arguments given after "--", which are passed to git
+// commit. Positional arguments without a preceding "--" are rejected.
+func commitArgs(args, rest []string) ([]string, error) {
+	if len(rest) == 0 {
+		return nil, nil
+	}
+	if len(args) <= len(rest) || args[len(args)-len(rest)-1] != "--" {
+		return nil, fmt.Errorf("unexpected argument %q, pass git commit arguments after --", rest[0])
+	}
+	if err := git.ValidateCommitArgs(rest); err != nil {
+		return nil, err
 	}
+	return rest, nil
 }
 
 func printVersion() {
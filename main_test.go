@@ -0,0 +1,212 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/denysvitali/git-cc/pkg/git"
+)
+
+func TestCommitArgs(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		rest     []string
+		expected []string
+		wantErr  bool
+	}{
+		{
+			name: "no extra args",
+			args: []string{"--version"},
+		},
+		{
+			name:     "args after separator",
+			args:     []string{"--", "--no-verify", "--allow-empty"},
+			rest:     []string{"--no-verify", "--allow-empty"},
+			expected: []string{"--no-verify", "--allow-empty"},
+		},
+		{
+			name:    "positional without separator",
+			args:    []string{"foo"},
+			rest:    []string{"foo"},
+			wantErr: true,
+		},
+		{
+			name:    "conflicting message flag",
+			args:    []string{"--", "-m", "other"},
+			rest:    []string{"-m", "other"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := commitArgs(tt.args, tt.rest)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestWriteMessage(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "COMMIT_EDITMSG")
+
+	if err := writeMessage("feat: add dry run", false, path); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read output file: %v", err)
+	}
+	if string(content) != "feat: add dry run\n" {
+		t.Errorf("expected message with trailing newline, got %q", string(content))
+	}
+}
+
+func TestMessageFlags(t *testing.T) {
+	var flags messageFlags
+	fs := flag.NewFlagSet("git-cc", flag.ContinueOnError)
+	flags.register(fs)
+
+	if flags.given() {
+		t.Error("expected no message flags before parsing")
+	}
+
+	err := fs.Parse([]string{"--type", "feat", "--scope", "cli", "--subject", "add flags",
+		"--footer", "Refs=#12", "--footer", "BREAKING CHANGE=removed -x", "--breaking"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !flags.given() {
+		t.Error("expected message flags to be given")
+	}
+
+	expected := "feat(cli)!: add flags\n\nRefs: #12\nBREAKING CHANGE: removed -x"
+	if message := flags.fields().String(); message != expected {
+		t.Errorf("expected %q, got %q", expected, message)
+	}
+
+	if err := flags.footers.Set("no separator"); err == nil {
+		t.Error("expected error for a footer without =")
+	}
+}
+
+func TestUsePlainUI(t *testing.T) {
+	if plain, err := usePlainUI("plain"); err != nil || !plain {
+		t.Errorf("expected plain prompts, got %v, %v", plain, err)
+	}
+	if plain, err := usePlainUI("tui"); err != nil || plain {
+		t.Errorf("expected the full interface, got %v, %v", plain, err)
+	}
+	// Tests do not run in a terminal
+	if plain, err := usePlainUI("auto"); err != nil || !plain {
+		t.Errorf("expected plain prompts without a terminal, got %v, %v", plain, err)
+	}
+	if _, err := usePlainUI("gui"); err == nil {
+		t.Error("expected error for an unknown mode")
+	}
+}
+
+func TestJSONOutput(t *testing.T) {
+	tempDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+
+	os.Chdir(tempDir)
+	commands := [][]string{
+		{"git", "init", "-q"},
+		{"git", "config", "user.name", "Test User"},
+		{"git", "config", "user.email", "test@example.com"},
+	}
+	for _, args := range commands {
+		if err := exec.Command(args[0], args[1:]...).Run(); err != nil {
+			t.Fatalf("failed to run %v: %v", args, err)
+		}
+	}
+	os.WriteFile("a.txt", []byte("a\n"), 0644)
+	exec.Command("git", "add", "a.txt").Run()
+
+	msg := "feat(io)!: add a\n\nExplain why.\n\nRefs: #1"
+	out := outcome{message: msg, result: git.CommitWithResult(context.Background(), msg, git.CommitOptions{})}
+	output := newJSONOutput(out, false, nil)
+
+	if !output.Success || len(output.SHA) != 40 || output.DurationMS < 0 {
+		t.Errorf("expected a successful commit, got %+v", output)
+	}
+	if !reflect.DeepEqual(output.StagedFiles, []string{"a.txt"}) {
+		t.Errorf("expected the committed files, got %v", output.StagedFiles)
+	}
+	expected := &jsonMessage{
+		Text: msg, Conventional: true, Type: "feat", Scope: "io", Breaking: true, BreakingChange: "add a",
+		Subject: "add a", Body: "Explain why.", Footers: []jsonFooter{{Token: "Refs", Separator: ": ", Value: "#1"}},
+	}
+	if !reflect.DeepEqual(output.Message, expected) {
+		t.Errorf("expected message %+v, got %+v", expected, output.Message)
+	}
+
+	os.WriteFile(filepath.Join(".git", "hooks", "pre-commit"), []byte("#!/bin/sh\necho no >&2\nexit 2\n"), 0755)
+	os.WriteFile("b.txt", []byte("b\n"), 0644)
+	exec.Command("git", "add", "b.txt").Run()
+
+	out = outcome{message: "fix: b", result: git.CommitWithResult(context.Background(), "fix: b", git.CommitOptions{})}
+	// b.txt was staged after the start of the run, as from the interface
+	var buf bytes.Buffer
+	if err := writeJSON(&buf, newJSONOutput(out, false, stagedFilesAfter(out, nil))); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var decoded map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, buf.String())
+	}
+	jsonErr, _ := decoded["error"].(map[string]any)
+	if decoded["success"] != false || jsonErr["type"] != "hook_failed" || jsonErr["hook"] != "pre-commit" {
+		t.Errorf("expected a failed pre-commit hook, got %s", buf.String())
+	}
+	if files, _ := decoded["staged_files"].([]any); len(files) != 1 || files[0] != "b.txt" {
+		t.Errorf("expected the staged files, got %s", buf.String())
+	}
+}
+
+func TestSummaryLine(t *testing.T) {
+	tempDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+
+	os.Chdir(tempDir)
+	commands := [][]string{
+		{"git", "init", "-q", "-b", "main"},
+		{"git", "config", "user.name", "Test User"},
+		{"git", "config", "user.email", "test@example.com"},
+	}
+	for _, args := range commands {
+		if err := exec.Command(args[0], args[1:]...).Run(); err != nil {
+			t.Fatalf("failed to run %v: %v", args, err)
+		}
+	}
+	os.WriteFile("a.txt", []byte("a\nb\n"), 0644)
+	os.WriteFile("b.txt", []byte("b\n"), 0644)
+	exec.Command("git", "add", ".").Run()
+
+	result := git.CommitWithResult(context.Background(), "feat: add a\n\nBody.", git.CommitOptions{})
+	if !result.Success {
+		t.Fatalf("failed to commit: %s", result.Details)
+	}
+
+	expected := "[main " + result.SHA[:7] + "] feat: add a (2 files changed, +3 -0)"
+	if line := summaryLine(result.SHA, "feat: add a\n\nBody."); line != expected {
+		t.Errorf("expected %q, got %q", expected, line)
+	}
+}
@@ -0,0 +1,250 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/denysvitali/git-cc/pkg/conventional"
+	"github.com/denysvitali/git-cc/pkg/git"
+)
+
+// outcome is what a run did, whichever way the message was entered.
+type outcome struct {
+	// message is the message built, or "" if the user quit before.
+	message string
+	// result is the result of the commit, or nil if none was made.
+	result *git.CommitResult
+	// branch is the branch created to avoid committing to a protected one.
+	branch string
+	// conflicted is set when the run stopped on unresolved conflicts.
+	conflicted bool
+}
+
+// summaryLine describes the new commit in one line, the way git commit does,
+// such as "[main 1a2b3c4] feat: add a (2 files changed, +10 -3)". Parts that
+// cannot be read are left out.
+func summaryLine(sha, message string) string {
+	branch, err := git.CurrentBranch()
+	if err != nil || branch == "" {
+		branch = "detached HEAD"
+	}
+	header, _, _ := strings.Cut(message, "\n")
+	line := fmt.Sprintf("[%s %s] %s", branch, git.ShortSHA(sha), header)
+
+	files, err := git.CommitStats(sha)
+	if err != nil {
+		return line
+	}
+	var insertions, deletions int
+	for _, file := range files {
+		insertions += file.Insertions
+		deletions += file.Deletions
+	}
+	changed := "files changed"
+	if len(files) == 1 {
+		changed = "file changed"
+	}
+	return fmt.Sprintf("%s (%d %s, +%d -%d)", line, len(files), changed, insertions, deletions)
+}
+
+// jsonOutput is the result printed by --output json.
+type jsonOutput struct {
+	Success     bool         `json:"success"`
+	DryRun      bool         `json:"dry_run,omitempty"`
+	SHA         string       `json:"sha,omitempty"`
+	Branch      string       `json:"branch,omitempty"`
+	Message     *jsonMessage `json:"message,omitempty"`
+	StagedFiles []string     `json:"staged_files"`
+	Warnings    []string     `json:"warnings,omitempty"`
+	Error       *jsonError   `json:"error,omitempty"`
+	DurationMS  int64        `json:"duration_ms"`
+	Hooks       []jsonHook   `json:"hooks,omitempty"`
+}
+
+// jsonMessage is a commit message split into its fields.
+type jsonMessage struct {
+	Text string `json:"text"`
+	// Conventional is false if the header does not follow the specification,
+	// in which case it is returned as the subject.
+	Conventional bool   `json:"conventional"`
+	Type         string `json:"type,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+	// Breaking is set by "!" in the header or by a BREAKING CHANGE footer,
+	// whose value is BreakingChange.
+	Breaking       bool         `json:"breaking"`
+	BreakingChange string       `json:"breaking_change,omitempty"`
+	Subject        string       `json:"subject"`
+	Body           string       `json:"body,omitempty"`
+	Footers        []jsonFooter `json:"footers,omitempty"`
+}
+
+// jsonFooter is a footer of a commit message, such as "Refs: #12".
+type jsonFooter struct {
+	Token     string `json:"token"`
+	Separator string `json:"separator"`
+	Value     string `json:"value"`
+}
+
+// jsonError describes why a commit failed.
+type jsonError struct {
+	// Type is the name of a git.ErrorType, or one of runErrorTypes for
+	// failures before committing.
+	Type         string `json:"type"`
+	Message      string `json:"message"`
+	Details      string `json:"details,omitempty"`
+	Hint         string `json:"hint,omitempty"`
+	ExitCode     int    `json:"exit_code"`
+	Hook         string `json:"hook,omitempty"`
+	HookExitCode int    `json:"hook_exit_code,omitempty"`
+	Stdout       string `json:"stdout,omitempty"`
+	Stderr       string `json:"stderr,omitempty"`
+}
+
+// jsonHook is a hook run by git.
+type jsonHook struct {
+	Name       string `json:"name"`
+	ExitCode   int    `json:"exit_code"`
+	DurationMS int64  `json:"duration_ms"`
+}
+
+// stagedFilesAfter returns the files staged at the end of a run that made no
+// commit, as files may have been staged in the interface since the start. It
+// returns before if they cannot be read.
+func stagedFilesAfter(out outcome, before []string) []string {
+	if out.result != nil && out.result.SHA != "" {
+		return before
+	}
+	files, err := git.GetStagedFiles()
+	if err != nil {
+		return before
+	}
+	return files
+}
+
+// newJSONOutput describes the outcome of a run. stagedFiles are the files
+// staged when no commit was made.
+func newJSONOutput(out outcome, dryRun bool, stagedFiles []string) jsonOutput {
+	output := jsonOutput{
+		DryRun:      dryRun,
+		Branch:      out.branch,
+		StagedFiles: stagedFiles,
+	}
+	text := out.message
+	if dryRun {
+		output.Success = text != ""
+	}
+
+	if result := out.result; result != nil {
+		output.Success = result.Success
+		output.SHA = result.SHA
+		output.Warnings = result.Warnings
+		output.DurationMS = result.Duration.Milliseconds()
+		for _, hook := range result.Hooks {
+			output.Hooks = append(output.Hooks, jsonHook{
+				Name:       hook.Name,
+				ExitCode:   hook.ExitCode,
+				DurationMS: hook.Duration.Milliseconds(),
+			})
+		}
+		if !result.Success {
+			output.Error = newJSONError(result)
+		}
+	}
+
+	if output.SHA != "" {
+		// Report what was committed, including changes made by hooks
+		if committed, err := git.CommitMessage(output.SHA); err == nil {
+			text = strings.TrimSpace(committed)
+		}
+		if stats, err := git.CommitStats(output.SHA); err == nil {
+			output.StagedFiles = make([]string, len(stats))
+			for i, stat := range stats {
+				output.StagedFiles[i] = stat.Path
+			}
+		}
+	}
+	if output.StagedFiles == nil {
+		output.StagedFiles = []string{}
+	}
+
+	if text != "" {
+		output.Message = newJSONMessage(text)
+	}
+	return output
+}
+
+// newJSONMessage splits a commit message into its fields.
+func newJSONMessage(text string) *jsonMessage {
+	msg, err := conventional.Parse(text)
+	jsonMsg := &jsonMessage{
+		Text:           text,
+		Conventional:   err == nil,
+		Type:           msg.Type,
+		Scope:          msg.Scope,
+		Breaking:       msg.IsBreaking(),
+		BreakingChange: msg.BreakingChange(),
+		Subject:        msg.Subject,
+		Body:           msg.Body,
+	}
+	for _, footer := range msg.Footers {
+		jsonMsg.Footers = append(jsonMsg.Footers, jsonFooter{
+			Token:     footer.Token,
+			Separator: footer.Separator,
+			Value:     footer.Value,
+		})
+	}
+	return jsonMsg
+}
+
+func newJSONError(result *git.CommitResult) *jsonError {
+	jsonErr := &jsonError{
+		Type:     result.Type.String(),
+		Message:  result.Message,
+		Details:  result.Details,
+		Hint:     result.Hint,
+		ExitCode: -1,
+	}
+	if commitErr := result.Error; commitErr != nil {
+		jsonErr.ExitCode = commitErr.ExitCode
+		jsonErr.Hook = commitErr.Hook
+		jsonErr.HookExitCode = commitErr.HookExitCode
+		jsonErr.Stdout = commitErr.Stdout
+		jsonErr.Stderr = commitErr.Stderr
+	}
+	return jsonErr
+}
+
+// runErrorTypes name the failures before committing in JSON output, by the
+// exit code of the run. Those git reports as well use the name of the
+// git.ErrorType.
+var runErrorTypes = map[int]string{
+	exitInternal:      git.ErrorTypeUnknown.String(),
+	exitUsage:         "usage",
+	exitNotRepository: git.ErrorTypeNotInRepo.String(),
+	exitNothingStaged: git.ErrorTypeNoChanges.String(),
+	exitLintFailed:    "lint_failed",
+	exitAborted:       "aborted",
+	exitConflict:      git.ErrorTypeMergeConflict.String(),
+}
+
+// newJSONFailure describes a run that stopped with err and the exit code
+// code without committing.
+func newJSONFailure(code int, err error) jsonOutput {
+	errType, ok := runErrorTypes[code]
+	if !ok {
+		errType = git.ErrorTypeUnknown.String()
+	}
+	return jsonOutput{
+		StagedFiles: []string{},
+		Error:       &jsonError{Type: errType, Message: err.Error(), ExitCode: -1},
+	}
+}
+
+// writeJSON writes v as indented JSON.
+func writeJSON(w io.Writer, v any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
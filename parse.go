@@ -0,0 +1,54 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// runParse implements "git cc parse", which prints the commit message read
+// from a file, or from stdin without one, split into its fields as JSON. It
+// returns exitLintFailed if the header is not conventional.
+func runParse(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("git cc parse", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: git cc parse [file]\n\n"+
+			"Prints the commit message in file, or read from stdin, as JSON.\n")
+	}
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if fs.NArg() > 1 {
+		fs.Usage()
+		return exitUsage
+	}
+
+	var data []byte
+	var err error
+	if path := fs.Arg(0); path == "" || path == "-" {
+		data, err = io.ReadAll(stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: failed to read the message: %v\n", err)
+		return exitInternal
+	}
+
+	msg := newJSONMessage(strings.TrimSpace(string(data)))
+	if err := writeJSON(stdout, msg); err != nil {
+		fmt.Fprintf(stderr, "Error writing JSON output: %v\n", err)
+		return exitInternal
+	}
+	if !msg.Conventional {
+		return exitLintFailed
+	}
+	return exitOK
+}
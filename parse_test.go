@@ -0,0 +1,45 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunParse(t *testing.T) {
+	text := "feat(cli): add parse\n\nPrints JSON.\n\nBREAKING-CHANGE: exit codes\n  changed\nFixes #12\n"
+	var stdout, stderr strings.Builder
+	if code := runParse(nil, strings.NewReader(text), &stdout, &stderr); code != exitOK {
+		t.Fatalf("expected exit code %d, got %d: %s", exitOK, code, stderr.String())
+	}
+
+	var msg jsonMessage
+	if err := json.Unmarshal([]byte(stdout.String()), &msg); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, stdout.String())
+	}
+	if !msg.Conventional || msg.Type != "feat" || msg.Scope != "cli" || msg.Body != "Prints JSON." {
+		t.Errorf("unexpected message %+v", msg)
+	}
+	if !msg.Breaking || msg.BreakingChange != "exit codes\n  changed" {
+		t.Errorf("expected the breaking change from the footer, got %+v", msg)
+	}
+	if len(msg.Footers) != 2 || msg.Footers[1] != (jsonFooter{Token: "Fixes", Separator: " #", Value: "12"}) {
+		t.Errorf("unexpected footers %+v", msg.Footers)
+	}
+
+	path := filepath.Join(t.TempDir(), "COMMIT_EDITMSG")
+	os.WriteFile(path, []byte("Update readme\n"), 0644)
+	stdout.Reset()
+	if code := runParse([]string{path}, strings.NewReader(""), &stdout, &stderr); code != exitLintFailed {
+		t.Errorf("expected exit code %d for a message that is not conventional, got %d", exitLintFailed, code)
+	}
+	if !strings.Contains(stdout.String(), `"subject": "Update readme"`) {
+		t.Errorf("expected the header as the subject, got %s", stdout.String())
+	}
+
+	if code := runParse([]string{"a", "b"}, strings.NewReader(""), &stdout, &stderr); code != exitUsage {
+		t.Errorf("expected exit code %d for extra arguments, got %d", exitUsage, code)
+	}
+}
@@ -0,0 +1,184 @@
+// Package config loads git-cc settings from the "cc" section of git config.
+package config
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/denysvitali/git-cc/pkg/git"
+)
+
+// Section is the git config section holding git-cc settings.
+const Section = "cc"
+
+// Config holds the user-configurable settings of git-cc.
+type Config struct {
+	// CommitArgs are extra arguments passed to every git commit invocation,
+	// read from cc.commitArgs. Each value is split on whitespace.
+	CommitArgs []string
+	// AllowNoVerify offers to retry a commit with --no-verify after a hook
+	// failed, read from cc.allowNoVerify.
+	AllowNoVerify bool
+	// Timeout limits how long a commit and its hooks may run, read from
+	// cc.timeout. Zero means no limit.
+	Timeout time.Duration
+	// HistorySize is how many committed messages are kept for recall, read
+	// from cc.historySize. Zero disables the history.
+	HistorySize int
+	// ProtectedBranches are glob patterns, such as "main" or "release/*", of
+	// branches that should not be committed to directly, read from
+	// cc.protectedBranches. Each value is split on whitespace.
+	ProtectedBranches []string
+	// BlockProtectedBranches refuses commits to protected branches instead of
+	// warning about them, read from cc.protectedBranchAction ("warn" or
+	// "block").
+	BlockProtectedBranches bool
+	// BlockDetachedHead stops commits on a detached HEAD until they are
+	// confirmed, read from cc.blockDetachedHead. Otherwise they are only
+	// warned about.
+	BlockDetachedHead bool
+	// SummaryDelay closes the summary shown after a commit on its own once it
+	// elapsed, read from cc.summaryDelay. Zero waits for a key press.
+	SummaryDelay time.Duration
+}
+
+// DefaultHistorySize is the HistorySize used when cc.historySize is unset.
+const DefaultHistorySize = 100
+
+// Load reads the git-cc settings visible from the current repository,
+// including global and system git config.
+func Load() (*Config, error) {
+	values, err := git.ConfigValues(Section)
+	if err != nil {
+		return nil, err
+	}
+	return fromValues(values)
+}
+
+func fromValues(values map[string][]string) (*Config, error) {
+	cfg := &Config{}
+
+	for _, value := range values["commitargs"] {
+		cfg.CommitArgs = append(cfg.CommitArgs, strings.Fields(value)...)
+	}
+	if err := git.ValidateCommitArgs(cfg.CommitArgs); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if cfg.AllowNoVerify, err = boolValue(values, "allownoverify"); err != nil {
+		return nil, err
+	}
+
+	if cfg.BlockDetachedHead, err = boolValue(values, "blockdetachedhead"); err != nil {
+		return nil, err
+	}
+
+	if cfg.Timeout, err = durationValue(values, "timeout"); err != nil {
+		return nil, err
+	}
+
+	if cfg.SummaryDelay, err = durationValue(values, "summarydelay"); err != nil {
+		return nil, err
+	}
+
+	if cfg.HistorySize, err = intValue(values, "historysize", DefaultHistorySize); err != nil {
+		return nil, err
+	}
+
+	for _, value := range values["protectedbranches"] {
+		for _, pattern := range strings.Fields(value) {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("invalid pattern %q for %s.protectedBranches", pattern, Section)
+			}
+			cfg.ProtectedBranches = append(cfg.ProtectedBranches, pattern)
+		}
+	}
+
+	switch action := stringValue(values, "protectedbranchaction"); action {
+	case "", "warn":
+	case "block":
+		cfg.BlockProtectedBranches = true
+	default:
+		return nil, fmt.Errorf("invalid value %q for %s.protectedBranchAction, expected warn or block", action, Section)
+	}
+
+	return cfg, nil
+}
+
+// IsProtectedBranch reports whether branch matches one of the protected
+// branch patterns.
+func IsProtectedBranch(patterns []string, branch string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, branch); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// stringValue returns the last value of a key, or "" if it is unset.
+func stringValue(values map[string][]string, key string) string {
+	entries := values[key]
+	if len(entries) == 0 {
+		return ""
+	}
+	return strings.TrimSpace(entries[len(entries)-1])
+}
+
+// durationValue returns the last value of a duration key, such as "90s" or
+// "2m". A plain number is a number of seconds.
+func durationValue(values map[string][]string, key string) (time.Duration, error) {
+	entries := values[key]
+	if len(entries) == 0 {
+		return 0, nil
+	}
+
+	value := strings.TrimSpace(entries[len(entries)-1])
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second, nil
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration < 0 {
+		return 0, fmt.Errorf("invalid duration %q for %s.%s", value, Section, key)
+	}
+	return duration, nil
+}
+
+// intValue returns the last value of a non-negative integer key, or def if
+// the key is unset.
+func intValue(values map[string][]string, key string, def int) (int, error) {
+	entries := values[key]
+	if len(entries) == 0 {
+		return def, nil
+	}
+
+	value := strings.TrimSpace(entries[len(entries)-1])
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid number %q for %s.%s", value, Section, key)
+	}
+	return n, nil
+}
+
+// boolValue returns the last value of a boolean key, accepting the same
+// spellings as git. A key without a value is true.
+func boolValue(values map[string][]string, key string) (bool, error) {
+	entries := values[key]
+	if len(entries) == 0 {
+		return false, nil
+	}
+
+	switch value := strings.ToLower(entries[len(entries)-1]); value {
+	case "", "true", "yes", "on", "1":
+		return true, nil
+	case "false", "no", "off", "0":
+		return false, nil
+	default:
+		return false, fmt.Errorf("invalid boolean value %q for %s.%s", value, Section, key)
+	}
+}
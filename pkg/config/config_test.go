@@ -0,0 +1,185 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFromValues(t *testing.T) {
+	cfg, err := fromValues(map[string][]string{
+		"commitargs": {"--no-verify --signoff", "--date=now"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{"--no-verify", "--signoff", "--date=now"}
+	if !reflect.DeepEqual(cfg.CommitArgs, expected) {
+		t.Errorf("expected commit args %v, got %v", expected, cfg.CommitArgs)
+	}
+}
+
+func TestFromValuesEmpty(t *testing.T) {
+	cfg, err := fromValues(map[string][]string{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cfg.CommitArgs) != 0 {
+		t.Errorf("expected no commit args, got %v", cfg.CommitArgs)
+	}
+	if cfg.HistorySize != DefaultHistorySize {
+		t.Errorf("expected default history size, got %d", cfg.HistorySize)
+	}
+}
+
+func TestFromValuesConflictingArgs(t *testing.T) {
+	_, err := fromValues(map[string][]string{
+		"commitargs": {"-m override"},
+	})
+	if err == nil {
+		t.Error("expected error for conflicting commit args")
+	}
+}
+
+func TestBoolValue(t *testing.T) {
+	tests := []struct {
+		values   []string
+		expected bool
+		wantErr  bool
+	}{
+		{values: nil, expected: false},
+		{values: []string{""}, expected: true},
+		{values: []string{"yes"}, expected: true},
+		{values: []string{"true", "off"}, expected: false},
+		{values: []string{"maybe"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		result, err := boolValue(map[string][]string{"key": tt.values}, "key")
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%v: expected error %v, got %v", tt.values, tt.wantErr, err)
+		}
+		if result != tt.expected {
+			t.Errorf("%v: expected %v, got %v", tt.values, tt.expected, result)
+		}
+	}
+}
+
+func TestFromValuesAllowNoVerify(t *testing.T) {
+	cfg, err := fromValues(map[string][]string{"allownoverify": {"true"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !cfg.AllowNoVerify {
+		t.Error("expected AllowNoVerify to be set")
+	}
+}
+
+func TestFromValuesBlockDetachedHead(t *testing.T) {
+	cfg, err := fromValues(map[string][]string{"blockdetachedhead": {"true"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !cfg.BlockDetachedHead {
+		t.Error("expected BlockDetachedHead to be set")
+	}
+}
+
+func TestFromValuesSummaryDelay(t *testing.T) {
+	cfg, err := fromValues(map[string][]string{"summarydelay": {"3s"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.SummaryDelay != 3*time.Second {
+		t.Errorf("expected a summary delay of 3s, got %v", cfg.SummaryDelay)
+	}
+}
+
+func TestDurationValue(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected time.Duration
+		wantErr  bool
+	}{
+		{value: "90s", expected: 90 * time.Second},
+		{value: "2m", expected: 2 * time.Minute},
+		{value: "30", expected: 30 * time.Second},
+		{value: "-1s", wantErr: true},
+		{value: "soon", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		result, err := durationValue(map[string][]string{"timeout": {tt.value}}, "timeout")
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q: expected error %v, got %v", tt.value, tt.wantErr, err)
+		}
+		if result != tt.expected {
+			t.Errorf("%q: expected %v, got %v", tt.value, tt.expected, result)
+		}
+	}
+}
+
+func TestIntValue(t *testing.T) {
+	tests := []struct {
+		values   []string
+		expected int
+		wantErr  bool
+	}{
+		{values: nil, expected: 7},
+		{values: []string{"0"}, expected: 0},
+		{values: []string{"10", " 20 "}, expected: 20},
+		{values: []string{"-1"}, wantErr: true},
+		{values: []string{"many"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		result, err := intValue(map[string][]string{"key": tt.values}, "key", 7)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%v: expected error %v, got %v", tt.values, tt.wantErr, err)
+		}
+		if result != tt.expected {
+			t.Errorf("%v: expected %v, got %v", tt.values, tt.expected, result)
+		}
+	}
+}
+
+func TestFromValuesProtectedBranches(t *testing.T) {
+	cfg, err := fromValues(map[string][]string{
+		"protectedbranches":     {"main master", "release/*"},
+		"protectedbranchaction": {"warn", "block"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{"main", "master", "release/*"}
+	if !reflect.DeepEqual(cfg.ProtectedBranches, expected) {
+		t.Errorf("expected protected branches %v, got %v", expected, cfg.ProtectedBranches)
+	}
+	if !cfg.BlockProtectedBranches {
+		t.Error("expected commits to protected branches to be blocked")
+	}
+
+	if _, err := fromValues(map[string][]string{"protectedbranches": {"release/["}}); err == nil {
+		t.Error("expected error for an invalid pattern")
+	}
+	if _, err := fromValues(map[string][]string{"protectedbranchaction": {"ignore"}}); err == nil {
+		t.Error("expected error for an invalid action")
+	}
+}
+
+func TestIsProtectedBranch(t *testing.T) {
+	patterns := []string{"main", "release/*"}
+	for branch, expected := range map[string]bool{
+		"main":          true,
+		"release/1.2":   true,
+		"feat/main":     false,
+		"release/1/fix": false,
+		"":              false,
+	} {
+		if protected := IsProtectedBranch(patterns, branch); protected != expected {
+			t.Errorf("IsProtectedBranch(%q) = %v, expected %v", branch, protected, expected)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+// Package conventional parses and formats commit messages following the
+// Conventional Commits 1.0.0 specification.
+package conventional
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ErrNotConventional is returned by Parse when the header of a message does
+// not follow the specification.
+var ErrNotConventional = errors.New("not a conventional commit header")
+
+// Footer separators allowed by the specification.
+const (
+	SeparatorColon = ": "
+	SeparatorHash  = " #"
+)
+
+// Tokens of a breaking change footer, which the specification treats as
+// synonyms.
+const (
+	BreakingChange        = "BREAKING CHANGE"
+	BreakingChangeSynonym = "BREAKING-CHANGE"
+)
+
+// headerPattern splits a header into its type, scope, breaking change marker
+// and description.
+var headerPattern = regexp.MustCompile(`^([\w-]+)(?:\(([^()\n]*)\))?(!)?: (.*)$`)
+
+// footerPattern matches the first line of a footer, such as "Refs: #12",
+// "Fixes #3" or "BREAKING CHANGE: removed the flag".
+var footerPattern = regexp.MustCompile(`^(BREAKING CHANGE|[A-Za-z][A-Za-z0-9-]*)(: | #)(.*)$`)
+
+// tokenPattern matches the token of a footer. Tokens use "-" instead of
+// spaces, except for BREAKING CHANGE.
+var tokenPattern = regexp.MustCompile(`^(BREAKING CHANGE|[A-Za-z][A-Za-z0-9-]*)$`)
+
+// Footer is a footer of a message, such as "Refs: #12".
+type Footer struct {
+	Token string
+	// Separator is SeparatorColon or SeparatorHash.
+	Separator string
+	// Value may span several lines.
+	Value string
+}
+
+// NewFooter returns the footer "token: value", or an error if token is not a
+// valid footer token.
+func NewFooter(token, value string) (Footer, error) {
+	token = strings.TrimSpace(token)
+	if !tokenPattern.MatchString(token) {
+		return Footer{}, fmt.Errorf("invalid footer token %q", token)
+	}
+	return Footer{Token: token, Separator: SeparatorColon, Value: strings.TrimSpace(value)}, nil
+}
+
+// String returns the footer as written in a message.
+func (f Footer) String() string {
+	return f.Token + f.Separator + f.Value
+}
+
+// IsBreakingChange reports whether the footer describes a breaking change.
+func (f Footer) IsBreakingChange() bool {
+	return f.Token == BreakingChange || f.Token == BreakingChangeSynonym
+}
+
+// Valid reports whether the footer parses back unchanged: its token and
+// separator are valid and every line of its value after the first is a
+// continuation line.
+func (f Footer) Valid() bool {
+	if !tokenPattern.MatchString(f.Token) || (f.Separator != SeparatorColon && f.Separator != SeparatorHash) {
+		return false
+	}
+	lines := strings.Split(f.Value, "\n")
+	for _, line := range lines[1:] {
+		if !isContinuation(line) {
+			return false
+		}
+	}
+	return true
+}
+
+// IsFooter reports whether line starts a footer, such as "Refs: #12".
+func IsFooter(line string) bool {
+	return footerPattern.MatchString(line)
+}
+
+// isContinuation reports whether line continues the value of the footer
+// above it. Like git, such lines start with whitespace.
+func isContinuation(line string) bool {
+	return strings.TrimSpace(line) != "" && (line[0] == ' ' || line[0] == '\t')
+}
+
+// Message is a commit message split into the parts of the specification.
+type Message struct {
+	Type  string
+	Scope string
+	// Breaking is set by "!" in the header. IsBreaking also considers the
+	// footers.
+	Breaking bool
+	// Subject is the description after the type, or the whole header of a
+	// message that is not conventional.
+	Subject string
+	Body    string
+	Footers []Footer
+}
+
+// Header returns the first line of the message, such as "feat(ui)!: add". A
+// message without a type has its subject as the header.
+func (m Message) Header() string {
+	if m.Type == "" {
+		return m.Subject
+	}
+	header := m.Type
+	if m.Scope != "" {
+		header += "(" + m.Scope + ")"
+	}
+	if m.Breaking {
+		header += "!"
+	}
+	return header + ": " + m.Subject
+}
+
+// String returns the full message: the header, the body and the footers,
+// separated by blank lines.
+func (m Message) String() string {
+	parts := []string{m.Header()}
+	if body := strings.TrimSpace(m.Body); body != "" {
+		parts = append(parts, body)
+	}
+	if len(m.Footers) > 0 {
+		footers := make([]string, len(m.Footers))
+		for i, footer := range m.Footers {
+			footers[i] = footer.String()
+		}
+		parts = append(parts, strings.Join(footers, "\n"))
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// IsBreaking reports whether the message introduces a breaking change, either
+// with "!" in the header or with a BREAKING CHANGE footer.
+func (m Message) IsBreaking() bool {
+	return m.Breaking || m.BreakingChange() != ""
+}
+
+// BreakingChange returns the description of the breaking change: the value
+// of the first BREAKING CHANGE footer or, for a header marked with "!"
+// alone, the subject. It is "" if the message breaks nothing.
+func (m Message) BreakingChange() string {
+	for _, footer := range m.Footers {
+		if footer.IsBreakingChange() {
+			return footer.Value
+		}
+	}
+	if m.Breaking {
+		return m.Subject
+	}
+	return ""
+}
+
+// Parse splits a commit message into its parts. The footers are read from
+// the last paragraph, as described by SplitBody. If the header is not conventional, Parse
+// returns an error wrapping ErrNotConventional along with the message, whose
+// subject is then the whole header.
+func Parse(text string) (Message, error) {
+	text = strings.TrimSpace(strings.ReplaceAll(text, "\r\n", "\n"))
+	header, rest, _ := strings.Cut(text, "\n")
+
+	var m Message
+	m.Body, m.Footers = SplitBody(rest)
+
+	match := headerPattern.FindStringSubmatch(header)
+	if match == nil {
+		m.Subject = header
+		return m, fmt.Errorf("%w: %q", ErrNotConventional, header)
+	}
+	m.Type, m.Scope, m.Breaking, m.Subject = match[1], match[2], match[3] == "!", match[4]
+	return m, nil
+}
+
+// SplitBody splits the text after the header into the body and the footers
+// of its last paragraph. Like git interpret-trailers, the last paragraph is
+// read as footers only if each of its lines starts a footer or, starting
+// with whitespace, continues the value of the previous one. Otherwise it is
+// part of the body.
+func SplitBody(text string) (body string, footers []Footer) {
+	text = strings.TrimSpace(strings.ReplaceAll(text, "\r\n", "\n"))
+	if text == "" {
+		return "", nil
+	}
+
+	paragraphs := strings.Split(text, "\n\n")
+	last := strings.Split(strings.TrimLeft(paragraphs[len(paragraphs)-1], "\n"), "\n")
+	for i, line := range last {
+		if match := footerPattern.FindStringSubmatch(line); match != nil {
+			footers = append(footers, Footer{Token: match[1], Separator: match[2], Value: match[3]})
+		} else if i > 0 && isContinuation(line) {
+			footers[len(footers)-1].Value += "\n" + line
+		} else {
+			return text, nil
+		}
+	}
+
+	body = strings.TrimSpace(strings.Join(paragraphs[:len(paragraphs)-1], "\n\n"))
+	return body, footers
+}
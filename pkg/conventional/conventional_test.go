@@ -0,0 +1,168 @@
+package conventional
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected Message
+	}{
+		{
+			name:     "header only",
+			text:     "feat(parser)!: support footers\n",
+			expected: Message{Type: "feat", Scope: "parser", Breaking: true, Subject: "support footers"},
+		},
+		{
+			name: "body and footers",
+			text: "fix: handle errors\n\nFirst paragraph.\n\nSecond paragraph.\n\nRefs: #12\nFixes #3",
+			expected: Message{
+				Type: "fix", Subject: "handle errors", Body: "First paragraph.\n\nSecond paragraph.",
+				Footers: []Footer{
+					{Token: "Refs", Separator: SeparatorColon, Value: "#12"},
+					{Token: "Fixes", Separator: SeparatorHash, Value: "3"},
+				},
+			},
+		},
+		{
+			name: "breaking change synonym",
+			text: "refactor: drop -x\n\nBREAKING-CHANGE: -x is gone",
+			expected: Message{
+				Type: "refactor", Subject: "drop -x",
+				Footers: []Footer{{Token: "BREAKING-CHANGE", Separator: SeparatorColon, Value: "-x is gone"}},
+			},
+		},
+		{
+			name: "multi-line footer value",
+			text: "feat: add\n\nBREAKING CHANGE: the config moved\n to cc.* keys\n\tand was renamed\nReviewed-by: Ann",
+			expected: Message{
+				Type: "feat", Subject: "add",
+				Footers: []Footer{
+					{Token: "BREAKING CHANGE", Separator: SeparatorColon, Value: "the config moved\n to cc.* keys\n\tand was renamed"},
+					{Token: "Reviewed-by", Separator: SeparatorColon, Value: "Ann"},
+				},
+			},
+		},
+		{
+			name: "footer followed by prose",
+			text: "fix: wrap\n\nFixes #3\nby wrapping the lines",
+			expected: Message{
+				Type: "fix", Subject: "wrap", Body: "Fixes #3\nby wrapping the lines",
+			},
+		},
+		{
+			name:     "prose last paragraph",
+			text:     "docs: readme\r\n\r\nSee http://example.com for more.",
+			expected: Message{Type: "docs", Subject: "readme", Body: "See http://example.com for more."},
+		},
+	}
+
+	for _, tt := range tests {
+		m, err := Parse(tt.text)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+		}
+		if !reflect.DeepEqual(m, tt.expected) {
+			t.Errorf("%s: expected %+v, got %+v", tt.name, tt.expected, m)
+		}
+	}
+}
+
+func TestParseNotConventional(t *testing.T) {
+	m, err := Parse("Merge branch 'other'\n\nConflicts resolved.")
+	if !errors.Is(err, ErrNotConventional) {
+		t.Errorf("expected ErrNotConventional, got %v", err)
+	}
+	expected := Message{Subject: "Merge branch 'other'", Body: "Conflicts resolved."}
+	if !reflect.DeepEqual(m, expected) {
+		t.Errorf("expected %+v, got %+v", expected, m)
+	}
+	if m.String() != "Merge branch 'other'\n\nConflicts resolved." {
+		t.Errorf("expected the message to format unchanged, got %q", m.String())
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	messages := []string{
+		"feat: add",
+		"fix(ui)!: crash on resize",
+		"docs: readme\n\nMore.\n\nRefs: #12\nCloses #3",
+		"ci: bump\n\nBREAKING CHANGE: needs Go 1.23\n  and a new runner\nRefs: #1",
+		"chore: tidy\n\nBody line one\nline two.",
+	}
+	for _, text := range messages {
+		m, err := Parse(text)
+		if err != nil {
+			t.Errorf("unexpected error for %q: %v", text, err)
+		}
+		if m.String() != text {
+			t.Errorf("expected %q to round-trip, got %q", text, m.String())
+		}
+		again, _ := Parse(m.String())
+		if !reflect.DeepEqual(again, m) {
+			t.Errorf("expected %+v to round-trip, got %+v", m, again)
+		}
+	}
+}
+
+func TestBreakingChange(t *testing.T) {
+	tests := []struct {
+		message  Message
+		expected string
+	}{
+		{Message{Type: "feat", Subject: "add"}, ""},
+		{Message{Type: "feat", Breaking: true, Subject: "drop -x"}, "drop -x"},
+		{
+			Message{Type: "feat", Breaking: true, Subject: "drop -x", Footers: []Footer{
+				{Token: "Refs", Separator: SeparatorColon, Value: "#1"},
+				{Token: "BREAKING-CHANGE", Separator: SeparatorColon, Value: "use -y"},
+			}},
+			"use -y",
+		},
+	}
+	for _, tt := range tests {
+		if change := tt.message.BreakingChange(); change != tt.expected {
+			t.Errorf("expected %q, got %q", tt.expected, change)
+		}
+		if tt.message.IsBreaking() != (tt.expected != "") {
+			t.Errorf("expected IsBreaking to be %v for %+v", tt.expected != "", tt.message)
+		}
+	}
+}
+
+func TestNewFooter(t *testing.T) {
+	if footer, err := NewFooter("Refs", " #12"); err != nil || footer.String() != "Refs: #12" {
+		t.Errorf("expected \"Refs: #12\", got %q, %v", footer.String(), err)
+	}
+	footer, err := NewFooter("BREAKING CHANGE", "removed -x")
+	if err != nil || !footer.IsBreakingChange() {
+		t.Errorf("expected a breaking change footer, got %+v, %v", footer, err)
+	}
+	if _, err := NewFooter("not a token", "x"); err == nil {
+		t.Error("expected error for an invalid token")
+	}
+}
+
+func TestFooterValid(t *testing.T) {
+	tests := []struct {
+		footer   Footer
+		expected bool
+	}{
+		{Footer{Token: "Refs", Separator: SeparatorHash, Value: "12"}, true},
+		{Footer{Token: "Note", Separator: SeparatorColon, Value: "one\n two"}, true},
+		{Footer{Token: "Note", Separator: SeparatorColon, Value: "one\ntwo"}, false},
+		{Footer{Token: "Note", Separator: SeparatorColon, Value: "one\nRefs: #1"}, false},
+		{Footer{Token: "Note", Separator: SeparatorColon, Value: "one\n\n two"}, false},
+		{Footer{Token: "not a token", Separator: SeparatorColon, Value: "x"}, false},
+		{Footer{Token: "Refs", Separator: "=", Value: "x"}, false},
+	}
+	for _, tt := range tests {
+		if valid := tt.footer.Valid(); valid != tt.expected {
+			t.Errorf("expected Valid() = %v for %+v", tt.expected, tt.footer)
+		}
+	}
+}
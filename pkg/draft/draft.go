@@ -0,0 +1,112 @@
+// Package draft persists the commit message in progress, so that it survives
+// a failed commit or a closed terminal.
+package draft
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/denysvitali/git-cc/pkg/git"
+)
+
+// fileName is the location of the draft inside the git directory.
+const fileName = "git-cc/draft.json"
+
+// Draft is a commit message in progress.
+type Draft struct {
+	Type    string   `json:"type"`
+	Scope   string   `json:"scope,omitempty"`
+	Subject string   `json:"subject,omitempty"`
+	Body    string   `json:"body,omitempty"`
+	Footers []string `json:"footers,omitempty"`
+}
+
+// IsEmpty reports whether the draft holds nothing worth restoring.
+func (d *Draft) IsEmpty() bool {
+	return d.Scope == "" && d.Subject == "" && d.Body == "" && len(d.Footers) == 0
+}
+
+// Text returns the body followed by the footers, as written in a message.
+func (d *Draft) Text() string {
+	parts := make([]string, 0, 2)
+	if d.Body != "" {
+		parts = append(parts, d.Body)
+	}
+	if len(d.Footers) > 0 {
+		parts = append(parts, strings.Join(d.Footers, "\n"))
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// Path returns the location of the draft of the current repository.
+func Path() (string, error) {
+	return git.GitPath(fileName)
+}
+
+// Load returns the saved draft, or nil if there is none.
+func Load() (*Draft, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read draft: %w", err)
+	}
+
+	var d Draft
+	if err := json.Unmarshal(data, &d); err != nil {
+		return nil, fmt.Errorf("failed to parse draft %s: %w", path, err)
+	}
+	return &d, nil
+}
+
+// Save stores d, replacing any previous draft. An empty draft is cleared.
+func Save(d *Draft) error {
+	if d.IsEmpty() {
+		return Clear()
+	}
+
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to save draft: %w", err)
+	}
+
+	data, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to save draft: %w", err)
+	}
+
+	// Write to a temporary file first so a crash never leaves half a draft
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to save draft: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to save draft: %w", err)
+	}
+	return nil
+}
+
+// Clear removes the saved draft, if any.
+func Clear() error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to clear draft: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package draft
+
+import (
+	"os"
+	"os/exec"
+	"reflect"
+	"testing"
+)
+
+func TestSaveLoadClear(t *testing.T) {
+	tempDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+
+	os.Chdir(tempDir)
+
+	if err := exec.Command("git", "init", "-q").Run(); err != nil {
+		t.Fatalf("failed to init git repo: %v", err)
+	}
+
+	if d, err := Load(); err != nil || d != nil {
+		t.Fatalf("expected no draft, got %+v, %v", d, err)
+	}
+
+	saved := &Draft{Type: "feat", Scope: "ui", Subject: "add drafts", Body: "Why.", Footers: []string{"Refs: #1"}}
+	if err := Save(saved); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := os.Stat(".git/git-cc/draft.json"); err != nil {
+		t.Errorf("expected draft in the git directory: %v", err)
+	}
+
+	loaded, err := Load()
+	if err != nil || !reflect.DeepEqual(loaded, saved) {
+		t.Errorf("expected %+v, got %+v, %v", saved, loaded, err)
+	}
+	if loaded.Text() != "Why.\n\nRefs: #1" {
+		t.Errorf("unexpected text %q", loaded.Text())
+	}
+
+	// Saving an empty draft clears it
+	if err := Save(&Draft{Type: "feat"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d, _ := Load(); d != nil {
+		t.Errorf("expected the draft to be cleared, got %+v", d)
+	}
+
+	if err := Clear(); err != nil {
+		t.Errorf("expected clearing a missing draft to succeed, got %v", err)
+	}
+}
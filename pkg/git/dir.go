@@ -0,0 +1,98 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// dir is the directory git commands run in. Paths exchanged with git are
+// relative to it. Empty means the working directory of the process. It is
+// set once at startup by SetDir, before any command runs, and not changed
+// afterwards.
+var dir string
+
+// startDir is the directory given to SetDir, before moving to the top level.
+// Pathspecs given by the user, such as those passed to git commit, are
+// relative to it. Empty means the working directory of the process.
+var startDir string
+
+// SetDir makes git commands run as if git-cc was started in path, like
+// git -C. Relative GIT_DIR and GIT_WORK_TREE are resolved against path, as
+// git does. Commands then run in the top-level directory of the work tree,
+// which the file paths reported by git are relative to. It must be called
+// at most once, before any other function of the package.
+func SetDir(path string) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("cannot change to %q: %w", path, err)
+	}
+	info, err := os.Stat(abs)
+	if err != nil {
+		return fmt.Errorf("cannot change to %q: %w", path, err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("cannot change to %q: not a directory", path)
+	}
+
+	for _, name := range []string{"GIT_DIR", "GIT_WORK_TREE"} {
+		if value := os.Getenv(name); value != "" && !filepath.IsAbs(value) {
+			if err := os.Setenv(name, filepath.Join(abs, value)); err != nil {
+				return err
+			}
+		}
+	}
+
+	dir, startDir = abs, abs
+	if output, err := command("rev-parse", "--show-toplevel").Output(); err == nil {
+		if top := strings.TrimSpace(string(output)); top != "" {
+			dir = top
+		}
+	}
+	return nil
+}
+
+// Dir returns the directory git commands run in, or "" for the working
+// directory of the process.
+func Dir() string {
+	return dir
+}
+
+// command returns a git command that runs in dir.
+func command(args ...string) *exec.Cmd {
+	return commandIn("", args...)
+}
+
+// commandIn returns a git command that runs in sub, a directory relative to
+// dir such as a submodule. An empty sub runs in dir.
+func commandIn(sub string, args ...string) *exec.Cmd {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = subDir(sub)
+	return cmd
+}
+
+// commandContext is like commandIn but is killed when ctx is done.
+func commandContext(ctx context.Context, sub string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = subDir(sub)
+	return cmd
+}
+
+func subDir(sub string) string {
+	if sub == "" {
+		return dir
+	}
+	return resolvePath(sub)
+}
+
+// resolvePath returns the location of a path relative to dir, such as a file
+// reported by git.
+func resolvePath(path string) string {
+	if dir == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
@@ -0,0 +1,157 @@
+package git
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// chdirOutside moves to an empty directory outside any repository for the
+// duration of the test and resets the directory used by git commands.
+func chdirOutside(t *testing.T) {
+	t.Helper()
+
+	originalDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("failed to get the working directory: %v", err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatalf("failed to change directory: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := os.Chdir(originalDir); err != nil {
+			t.Errorf("failed to restore the working directory: %v", err)
+		}
+		dir, startDir = "", ""
+	})
+}
+
+func TestSetDir(t *testing.T) {
+	repo, _ := filepath.EvalSymlinks(t.TempDir())
+	chdirOutside(t)
+
+	if err := exec.Command("git", "init", "-q", repo).Run(); err != nil {
+		t.Fatalf("failed to init git repo: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(repo, "sub"), 0755); err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, "sub", "a.txt"), []byte("a"), 0644); err != nil {
+		t.Fatalf("failed to create file: %v", err)
+	}
+
+	if IsInGitRepo() {
+		t.Fatal("expected the working directory not to be a repository")
+	}
+
+	// Starting in a subdirectory still runs git at the top level
+	if err := SetDir(filepath.Join(repo, "sub")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if Dir() != repo {
+		t.Errorf("expected %q, got %q", repo, Dir())
+	}
+	if !IsInGitRepo() {
+		t.Error("expected the repository to be found")
+	}
+
+	if err := StageFiles([]string{"sub/a.txt"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	staged, err := GetStagedFiles()
+	if err != nil || !reflect.DeepEqual(staged, []string{"sub/a.txt"}) {
+		t.Errorf("expected sub/a.txt to be staged, got %v, %v", staged, err)
+	}
+
+	hashes, err := HashWorkTreeFiles("", staged)
+	if err != nil || hashes["sub/a.txt"] == "" {
+		t.Errorf("expected the file to be hashed, got %v, %v", hashes, err)
+	}
+
+	path, err := GitPath("index")
+	if err != nil || path != filepath.Join(repo, ".git", "index") {
+		t.Errorf("expected an absolute git path, got %q, %v", path, err)
+	}
+
+	if err := SetDir(filepath.Join(repo, "missing")); err == nil {
+		t.Error("expected an error for a missing directory")
+	}
+}
+
+func TestSetDirGitEnv(t *testing.T) {
+	root, _ := filepath.EvalSymlinks(t.TempDir())
+	chdirOutside(t)
+
+	work := filepath.Join(root, "work")
+	if err := exec.Command("git", "init", "-q", work).Run(); err != nil {
+		t.Fatalf("failed to init git repo: %v", err)
+	}
+	if err := os.Rename(filepath.Join(work, ".git"), filepath.Join(root, "meta.git")); err != nil {
+		t.Fatalf("failed to move git directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(work, "a.txt"), []byte("a"), 0644); err != nil {
+		t.Fatalf("failed to create file: %v", err)
+	}
+
+	// Relative paths are resolved against the directory given to SetDir
+	t.Setenv("GIT_DIR", "meta.git")
+	t.Setenv("GIT_WORK_TREE", "work")
+	if err := SetDir(root); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if os.Getenv("GIT_DIR") != filepath.Join(root, "meta.git") {
+		t.Errorf("expected an absolute GIT_DIR, got %q", os.Getenv("GIT_DIR"))
+	}
+	if Dir() != work {
+		t.Errorf("expected %q, got %q", work, Dir())
+	}
+
+	files, err := GetWorkingTreeStatus()
+	if err != nil || len(files) != 1 || files[0].Path != "a.txt" {
+		t.Errorf("expected a.txt to be untracked, got %+v, %v", files, err)
+	}
+}
+
+func TestSetDirPathspec(t *testing.T) {
+	repo, _ := filepath.EvalSymlinks(t.TempDir())
+	chdirOutside(t)
+
+	commands := [][]string{
+		{"init", "-q", repo},
+		{"-C", repo, "config", "user.name", "Test User"},
+		{"-C", repo, "config", "user.email", "test@example.com"},
+	}
+	for _, args := range commands {
+		if output, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("failed to run git %v: %v\n%s", args, err, output)
+		}
+	}
+	if err := os.MkdirAll(filepath.Join(repo, "sub"), 0755); err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	for _, name := range []string{"a.txt", "b.txt"} {
+		if err := os.WriteFile(filepath.Join(repo, "sub", name), []byte(name), 0644); err != nil {
+			t.Fatalf("failed to create file: %v", err)
+		}
+	}
+
+	if err := SetDir(filepath.Join(repo, "sub")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := StageFiles([]string{"sub/a.txt", "sub/b.txt"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The pathspec is relative to the subdirectory, like with git commit
+	if err := Commit(context.Background(), "fix: commit a", CommitOptions{Args: []string{"--", "a.txt"}}); err != nil {
+		t.Fatalf("expected the pathspec to match, got %v", err)
+	}
+	staged, err := GetStagedFiles()
+	if err != nil || !reflect.DeepEqual(staged, []string{"sub/b.txt"}) {
+		t.Errorf("expected sub/b.txt to stay staged, got %v, %v", staged, err)
+	}
+}
@@ -0,0 +1,32 @@
+package git
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Editor returns the editor command configured for git, honouring
+// GIT_EDITOR, core.editor, VISUAL and EDITOR.
+func Editor() (string, error) {
+	output, err := command("var", "GIT_EDITOR").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to determine editor: %w", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// EditorCommand returns a command opening the given files of sub in the
+// editor. Like git, the editor is run through the shell so it may contain
+// arguments.
+func EditorCommand(sub string, files []string) (*exec.Cmd, error) {
+	editor, err := Editor()
+	if err != nil {
+		return nil, err
+	}
+	args := append([]string{"-c", editor + ` "$@"`, editor}, files...)
+	cmd := exec.Command("sh", args...)
+	// The files are relative to the directory git runs in
+	cmd.Dir = subDir(sub)
+	return cmd, nil
+}
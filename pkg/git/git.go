@@ -3,16 +3,42 @@ package git
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os/exec"
 	"strings"
+	"time"
 )
 
 type CommitError struct {
 	Type    ErrorType
 	Message string
-	Output  string
-	Err     error
+	// Hint is a one-line suggestion to fix the error.
+	Hint string
+	// Output is the combined output of git and its hooks.
+	Output string
+	Err    error
+
+	// ExitCode is the exit status of git, or -1 if it did not exit normally.
+	ExitCode int
+	// Stdout and Stderr are the separate output streams of git. Git writes
+	// the output of its hooks to Stderr.
+	Stdout string
+	Stderr string
+	// Duration is how long git ran.
+	Duration time.Duration
+
+	// Hook is the name of the hook that failed, such as "pre-commit", when
+	// Type is ErrorTypeHookFailed.
+	Hook string
+	// HookExitCode is the exit status of the failed hook.
+	HookExitCode int
+	// HookDuration is how long the failed hook ran.
+	HookDuration time.Duration
+	// Hooks are the hooks git ran before failing.
+	Hooks []HookRun
 }
 
 type ErrorType int
@@ -23,14 +49,109 @@ const (
 	ErrorTypeNoChanges
 	ErrorTypeMergeConflict
 	ErrorTypeNotInRepo
+	ErrorTypeCancelled
+	ErrorTypeTimeout
+	ErrorTypeIdentityMissing
+	ErrorTypeIndexLocked
+	ErrorTypeDetachedHead
+	ErrorTypeOperationInProgress
+	ErrorTypeEmptyMessage
+	ErrorTypeCommitMsgHookFailed
 )
 
+// errorTypeNames are the names of the error types in machine-readable
+// output.
+var errorTypeNames = map[ErrorType]string{
+	ErrorTypeUnknown:             "unknown",
+	ErrorTypeHookFailed:          "hook_failed",
+	ErrorTypeNoChanges:           "no_changes",
+	ErrorTypeMergeConflict:       "merge_conflict",
+	ErrorTypeNotInRepo:           "not_in_repo",
+	ErrorTypeCancelled:           "cancelled",
+	ErrorTypeTimeout:             "timeout",
+	ErrorTypeIdentityMissing:     "identity_missing",
+	ErrorTypeIndexLocked:         "index_locked",
+	ErrorTypeDetachedHead:        "detached_head",
+	ErrorTypeOperationInProgress: "operation_in_progress",
+	ErrorTypeEmptyMessage:        "empty_message",
+	ErrorTypeCommitMsgHookFailed: "commit_msg_hook_failed",
+}
+
+// String returns the name of the error type, such as "hook_failed".
+func (t ErrorType) String() string {
+	if name, ok := errorTypeNames[t]; ok {
+		return name
+	}
+	return errorTypeNames[ErrorTypeUnknown]
+}
+
+// MarshalText encodes the error type as its name.
+func (t ErrorType) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// Hint returns a one-line suggestion to fix errors of this type, or "" if
+// there is none.
+func (t ErrorType) Hint() string {
+	switch t {
+	case ErrorTypeHookFailed:
+		return "Fix the problems reported by the hook, then retry."
+	case ErrorTypeNoChanges:
+		return "Stage the changes to commit with git add."
+	case ErrorTypeMergeConflict:
+		return "Resolve the conflicts and stage the files with git add."
+	case ErrorTypeNotInRepo:
+		return "Run git-cc inside a git repository."
+	case ErrorTypeTimeout:
+		return "Raise cc.timeout or --timeout if the hooks need more time."
+	case ErrorTypeIdentityMissing:
+		return "Set your name and email with git config user.name and user.email."
+	case ErrorTypeIndexLocked:
+		return "Make sure no other git process is running, then remove .git/index.lock."
+	case ErrorTypeDetachedHead:
+		return "Create a branch with git switch -c <branch> so the commit is not lost."
+	case ErrorTypeOperationInProgress:
+		return "Finish the operation with --continue or undo it with --abort."
+	case ErrorTypeEmptyMessage:
+		return "Enter a message that is not only whitespace or comments."
+	case ErrorTypeCommitMsgHookFailed:
+		return "Edit the message so that the commit-msg hook accepts it."
+	}
+	return ""
+}
+
+// cancelGracePeriod is how long a cancelled commit may take to clean up after
+// being terminated before it is killed.
+const cancelGracePeriod = 5 * time.Second
+
+// CommitOptions configures a commit.
+type CommitOptions struct {
+	// Args are extra arguments passed to git commit after the message. They
+	// must pass ValidateCommitArgs.
+	Args []string
+	// Output, if set, receives the output of git and its hooks as it is
+	// produced. It must be safe for concurrent use.
+	Output io.Writer
+	// Timeout, if positive, limits how long git and its hooks may run.
+	Timeout time.Duration
+	// BlockDetachedHead fails the commit if HEAD is not on a branch, outside
+	// of a rebase or cherry-pick. Otherwise it is only warned about.
+	BlockDetachedHead bool
+	// AllowDetachedHead commits on a detached HEAD even if BlockDetachedHead
+	// is set, once the user confirmed it.
+	AllowDetachedHead bool
+	// Dir, if set, commits in this directory relative to the repository,
+	// such as a submodule, instead of in the repository itself. Submodules
+	// are usually on a detached HEAD, so it is not checked for them.
+	Dir string
+}
+
 func (e *CommitError) Error() string {
 	return fmt.Sprintf("git commit failed: %s", e.Message)
 }
 
 func (e *CommitError) IsHook() bool {
-	return e.Type == ErrorTypeHookFailed
+	return e.Type == ErrorTypeHookFailed || e.Type == ErrorTypeCommitMsgHookFailed
 }
 
 func (e *CommitError) GetDetails() string {
@@ -40,53 +161,320 @@ func (e *CommitError) GetDetails() string {
 	return e.Message
 }
 
-func Commit(message string) error {
-	cmd := exec.Command("git", "commit", "-m", message)
+// conflictingShortFlags are the git commit short options that would replace
+// the message built by git-cc.
+const conflictingShortFlags = "mFe"
+
+// valueShortFlags are git commit short options whose value may be attached,
+// as in -Skeyid, so the rest of the cluster is not a list of flags.
+const valueShortFlags = "CcFmStu"
+
+// conflictingLongFlags are the long forms of conflictingShortFlags. Git also
+// accepts them abbreviated, such as --mess.
+var conflictingLongFlags = []string{"--message", "--file", "--edit"}
+
+// ValidateCommitArgs rejects extra git commit arguments that conflict with the
+// message built by git-cc, such as -m, -F and -e.
+func ValidateCommitArgs(args []string) error {
+	for _, arg := range args {
+		if arg == "--" {
+			// Everything after this is a pathspec
+			return nil
+		}
+
+		if strings.HasPrefix(arg, "--") {
+			name, _, _ := strings.Cut(arg, "=")
+			for _, flag := range conflictingLongFlags {
+				if len(name) > len("--") && strings.HasPrefix(flag, name) {
+					return fmt.Errorf("git commit argument %q conflicts with the message built by git-cc", arg)
+				}
+			}
+			continue
+		}
+
+		if !strings.HasPrefix(arg, "-") || len(arg) == 1 {
+			continue
+		}
+
+		for _, c := range arg[1:] {
+			if strings.ContainsRune(conflictingShortFlags, c) {
+				return fmt.Errorf("git commit argument %q conflicts with the message built by git-cc", arg)
+			}
+			if strings.ContainsRune(valueShortFlags, c) {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// Commit runs git commit with the given message. Cancelling ctx or reaching
+// the timeout terminates git and every hook it started.
+func Commit(ctx context.Context, message string, opts CommitOptions) error {
+	_, err := commit(ctx, message, opts)
+	return err
+}
+
+// commitInfo describes the outcome of a successful commit.
+type commitInfo struct {
+	// sha is the hash of the new commit, or "" if HEAD could not be read.
+	sha      string
+	warnings []string
+	duration time.Duration
+	hooks    []HookRun
+}
+
+// commit runs git commit and decides whether it succeeded by whether HEAD
+// moved to a commit with the given message.
+func commit(ctx context.Context, message string, opts CommitOptions) (*commitInfo, error) {
+	if err := ValidateCommitArgs(opts.Args); err != nil {
+		return nil, err
+	}
+
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
+	var detachedWarning string
+	if opts.Dir == "" {
+		if err := checkDetachedHead(); err != nil {
+			if opts.BlockDetachedHead && !opts.AllowDetachedHead {
+				return nil, err
+			}
+			detachedWarning = "HEAD is detached, the commit is not on any branch"
+		}
+	}
+
+	before, beforeErr := headCommit(opts.Dir)
+
+	cmd := commandContext(ctx, opts.Dir, append([]string{"commit", "-m", message}, opts.Args...)...)
+	if opts.Dir == "" && startDir != "" {
+		// Pathspecs in Args are relative to where git-cc was started
+		cmd.Dir = startDir
+	}
+	group := newProcessGroup(cmd)
+	defer group.stop()
+	cmd.Cancel = group.terminate
+	cmd.WaitDelay = cancelGracePeriod
 
 	var outBuffer, errBuffer bytes.Buffer
 	cmd.Stdout = &outBuffer
 	cmd.Stderr = &errBuffer
+	if opts.Output != nil {
+		cmd.Stdout = io.MultiWriter(&outBuffer, opts.Output)
+		cmd.Stderr = io.MultiWriter(&errBuffer, opts.Output)
+	}
+
+	// Git reports the hooks it runs, with their exit status, in its trace2
+	// event stream. Without it hook failures are reported as generic errors.
+	trace, traceErr := newHookTrace()
+	if traceErr == nil {
+		defer trace.remove()
+		cmd.Env = append(cmd.Environ(), trace.env())
+	}
 
+	start := time.Now()
 	err := cmd.Run()
-	if err != nil {
-		output := outBuffer.String() + errBuffer.String()
-
-		// Check if output only contains warnings that can be ignored
-		outputLower := strings.ToLower(output)
-		if strings.Contains(outputLower, "(ignored)") &&
-			!strings.Contains(outputLower, "nothing to commit") &&
-			!strings.Contains(outputLower, "hook") &&
-			!strings.Contains(outputLower, "merge conflict") {
-			// This is just a warning, treat as success
-			return nil
+	duration := time.Since(start)
+
+	var hooks []HookRun
+	if traceErr == nil {
+		hooks = trace.hookRuns()
+	}
+
+	after, afterErr := headCommit(opts.Dir)
+	committed := beforeErr == nil && afterErr == nil && after != "" && after != before
+	warnings := commitWarnings(errBuffer.String())
+	if detachedWarning != "" {
+		warnings = append(warnings, detachedWarning)
+	}
+
+	if err == nil && committed {
+		if !hasMessage(opts.Dir, after, message) {
+			warnings = append(warnings, "The commit message was changed by a hook")
+		}
+		return &commitInfo{sha: after, warnings: warnings, duration: duration, hooks: hooks}, nil
+	}
+	if err == nil && (beforeErr != nil || afterErr != nil) {
+		// Without HEAD there is no telling, so trust the exit status of git
+		return &commitInfo{warnings: warnings, duration: duration, hooks: hooks}, nil
+	}
+	if err == nil {
+		// Arguments such as --dry-run make git succeed without committing
+		return nil, &CommitError{
+			Type:     ErrorTypeUnknown,
+			Message:  "Git exited without creating a commit",
+			Hint:     "Check the git commit arguments, such as --dry-run, for one that prevents committing.",
+			Output:   outBuffer.String() + errBuffer.String(),
+			Stdout:   outBuffer.String(),
+			Stderr:   errBuffer.String(),
+			Duration: duration,
+			Hooks:    hooks,
 		}
+	}
 
-		return parseCommitError(err, output)
+	if committed && hasMessage(opts.Dir, after, message) {
+		// The commit exists even though git reported a failure afterwards
+		warnings = append(warnings, fmt.Sprintf("git exited with status %d after creating the commit", exitCode(err)))
+		return &commitInfo{sha: after, warnings: warnings, duration: duration, hooks: hooks}, nil
 	}
 
-	return nil
+	output := outBuffer.String() + errBuffer.String()
+
+	if ctx.Err() != nil {
+		commitErr := contextError(ctx, opts.Timeout, output)
+		commitErr.Duration = duration
+		commitErr.Hooks = hooks
+		return nil, commitErr
+	}
+
+	var failed *hookRun
+	if traceErr == nil {
+		failed = trace.failedHook()
+	}
+	if failed != nil {
+		commitErr := hookError(err, failed, output, outBuffer.String(), errBuffer.String(), duration)
+		commitErr.Hooks = hooks
+		return nil, commitErr
+	}
+
+	commitErr := parseCommitError(err, output)
+	if opts.Dir == "" && (commitErr.Type == ErrorTypeUnknown || commitErr.Type == ErrorTypeMergeConflict) {
+		if op := OperationInProgress(); op != "" {
+			commitErr.Type = ErrorTypeOperationInProgress
+			commitErr.Message = fmt.Sprintf("A %s is in progress", op)
+			commitErr.Hint = fmt.Sprintf("Resolve the conflicts, then run git %s --continue, or git %s --abort.", op, op)
+		}
+	}
+	commitErr.Stdout = outBuffer.String()
+	commitErr.Stderr = errBuffer.String()
+	commitErr.Duration = duration
+	commitErr.Hooks = hooks
+	return nil, commitErr
+}
+
+// checkDetachedHead fails if HEAD is detached outside of a rebase or
+// cherry-pick, where commits are easily lost.
+func checkDetachedHead() *CommitError {
+	detached, err := isDetachedHead()
+	if err != nil || !detached || OperationInProgress() != "" {
+		return nil
+	}
+	return &CommitError{
+		Type:     ErrorTypeDetachedHead,
+		Message:  "HEAD is detached",
+		Hint:     ErrorTypeDetachedHead.Hint(),
+		ExitCode: -1,
+	}
+}
+
+// commitWarnings returns the warnings git printed to stderr.
+func commitWarnings(stderr string) []string {
+	var warnings []string
+	for _, line := range strings.Split(stderr, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(strings.ToLower(line), "warning:") {
+			warnings = append(warnings, line)
+		}
+	}
+	return warnings
+}
+
+// hookError describes a commit aborted by a failing hook.
+func hookError(err error, hook *hookRun, output, stdout, stderr string, duration time.Duration) *CommitError {
+	errType := ErrorTypeHookFailed
+	if hook.name == "commit-msg" {
+		// The message was rejected, not the staged changes
+		errType = ErrorTypeCommitMsgHookFailed
+	}
+	return &CommitError{
+		Type:         errType,
+		Message:      fmt.Sprintf("%s hook failed", capitalize(hook.name)),
+		Hint:         errType.Hint(),
+		Output:       output,
+		Err:          err,
+		ExitCode:     exitCode(err),
+		Stdout:       stdout,
+		Stderr:       stderr,
+		Duration:     duration,
+		Hook:         hook.name,
+		HookExitCode: hook.exitCode,
+		HookDuration: hook.duration,
+	}
+}
+
+// exitCode returns the exit status reported by err, or -1 if there is none.
+func exitCode(err error) int {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return -1
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+// contextError describes a commit stopped because ctx was done.
+func contextError(ctx context.Context, timeout time.Duration, output string) *CommitError {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		message := "Commit timed out"
+		if timeout > 0 {
+			message = fmt.Sprintf("Commit timed out after %s", timeout)
+		}
+		return &CommitError{
+			Type:    ErrorTypeTimeout,
+			Message: message,
+			Hint:    ErrorTypeTimeout.Hint(),
+			Output:  output,
+			Err:     ctx.Err(),
+		}
+	}
+	return &CommitError{Type: ErrorTypeCancelled, Message: "Commit cancelled", Output: output, Err: ctx.Err()}
 }
 
 func parseCommitError(err error, output string) *CommitError {
 	commitErr := &CommitError{
-		Err:     err,
-		Output:  output,
-		Type:    ErrorTypeUnknown,
-		Message: "Commit failed",
+		Err:      err,
+		Output:   output,
+		Type:     ErrorTypeUnknown,
+		Message:  "Commit failed",
+		ExitCode: exitCode(err),
 	}
 
 	outputStr := strings.ToLower(output)
 
 	switch {
-	case strings.Contains(outputStr, "hook"):
-		commitErr.Type = ErrorTypeHookFailed
-		commitErr.Message = "Pre-commit hook failed"
+	case strings.Contains(outputStr, "please tell me who you are") ||
+		strings.Contains(outputStr, "author identity unknown") ||
+		strings.Contains(outputStr, "empty ident name") ||
+		strings.Contains(outputStr, "unable to auto-detect email address"):
+		commitErr.Type = ErrorTypeIdentityMissing
+		commitErr.Message = "Your git identity is not configured"
+
+	case strings.Contains(outputStr, "index.lock"):
+		commitErr.Type = ErrorTypeIndexLocked
+		commitErr.Message = "The index is locked by another git process"
 
-	case strings.Contains(outputStr, "nothing to commit"):
+	case strings.Contains(outputStr, "empty commit message"):
+		commitErr.Type = ErrorTypeEmptyMessage
+		commitErr.Message = "The commit message is empty after cleanup"
+
+	case strings.Contains(outputStr, "nothing to commit") ||
+		strings.Contains(outputStr, "no changes added to commit") ||
+		strings.Contains(outputStr, "nothing added to commit"):
 		commitErr.Type = ErrorTypeNoChanges
 		commitErr.Message = "No changes to commit"
 
-	case strings.Contains(outputStr, "merge conflict") || strings.Contains(outputStr, "conflicts then run git commit"):
+	case strings.Contains(outputStr, "merge conflict") ||
+		strings.Contains(outputStr, "conflicts then run git commit") ||
+		strings.Contains(outputStr, "unresolved conflict"):
 		commitErr.Type = ErrorTypeMergeConflict
 		commitErr.Message = "Merge conflicts need to be resolved"
 
@@ -115,11 +503,12 @@ func parseCommitError(err error, output string) *CommitError {
 		}
 	}
 
+	commitErr.Hint = commitErr.Type.Hint()
 	return commitErr
 }
 
 func IsInGitRepo() bool {
-	cmd := exec.Command("git", "rev-parse", "--git-dir")
+	cmd := command("rev-parse", "--git-dir")
 	err := cmd.Run()
 	return err == nil
 }
@@ -131,12 +520,31 @@ func IsGitRepository() bool {
 
 type CommitResult struct {
 	Success bool
+	Type    ErrorType
 	Message string
 	Details string
+	// Hint is a one-line suggestion to fix a failed commit.
+	Hint string
+	// SHA is the hash of the new commit.
+	SHA string
+	// Warnings are the warnings reported by git for a successful commit.
+	Warnings []string
+	// Error is the error that failed the commit, if it was a CommitError.
+	Error *CommitError
+	// Duration is how long git ran.
+	Duration time.Duration
+	// Hooks are the hooks git ran.
+	Hooks []HookRun
 }
 
 func GetStagedFiles() ([]string, error) {
-	cmd := exec.Command("git", "diff", "--cached", "--name-only")
+	return GetStagedFilesIn("")
+}
+
+// GetStagedFilesIn returns the files staged in sub, such as a submodule. An
+// empty sub is the repository itself.
+func GetStagedFilesIn(sub string) ([]string, error) {
+	cmd := commandIn(sub, "diff", "--cached", "--name-only", "--no-renames")
 	var outBuffer bytes.Buffer
 	cmd.Stdout = &outBuffer
 
@@ -154,14 +562,102 @@ func GetStagedFiles() ([]string, error) {
 	return files, nil
 }
 
-func CommitWithResult(message string) *CommitResult {
-	err := Commit(message)
+// StagedDiff holds the changes staged in the index.
+type StagedDiff struct {
+	// Stat is the diffstat of the staged changes.
+	Stat  string
+	Files []*FileDiff
+}
+
+// GetStagedDiff returns the diffstat and the per-file diffs of the index
+// against HEAD.
+func GetStagedDiff() (*StagedDiff, error) {
+	statCmd := command("diff", "--cached", "--stat", "--no-color")
+	stat, err := statCmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get staged diffstat: %w", err)
+	}
+
+	diffCmd := command("diff", "--cached", "--no-color", "--no-ext-diff")
+	output, err := diffCmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get staged diff: %w", err)
+	}
+
+	diff := &StagedDiff{Stat: strings.TrimRight(string(stat), "\n")}
+	for _, chunk := range splitFileDiffs(string(output)) {
+		file, err := parseFileDiff(diffPath(chunk), chunk)
+		if err != nil {
+			return nil, err
+		}
+		diff.Files = append(diff.Files, file)
+	}
+	return diff, nil
+}
+
+// splitFileDiffs splits the output of git diff into one chunk per file.
+func splitFileDiffs(output string) []string {
+	var chunks []string
+	for _, part := range strings.Split(output, "\ndiff --git ") {
+		if part == "" {
+			continue
+		}
+		if !strings.HasPrefix(part, "diff --git ") {
+			part = "diff --git " + part
+		}
+		chunks = append(chunks, part)
+	}
+	return chunks
+}
+
+// diffPath returns the path a single-file diff applies to.
+func diffPath(chunk string) string {
+	for _, line := range strings.Split(chunk, "\n") {
+		if strings.HasPrefix(line, "@@") {
+			break
+		}
+		if path, ok := strings.CutPrefix(line, "+++ b/"); ok {
+			return path
+		}
+		if path, ok := strings.CutPrefix(line, "--- a/"); ok {
+			return path
+		}
+	}
+
+	// Binary and mode-only changes have no ---/+++ lines
+	header, _, _ := strings.Cut(chunk, "\n")
+	header = strings.TrimPrefix(header, "diff --git a/")
+	if i := strings.LastIndex(header, " b/"); i >= 0 {
+		return header[i+len(" b/"):]
+	}
+	return header
+}
+
+func CommitWithResult(ctx context.Context, message string, opts CommitOptions) *CommitResult {
+	info, err := commit(ctx, message, opts)
+	result := NewCommitResult(err)
+	if info != nil {
+		result.SHA = info.sha
+		result.Warnings = info.warnings
+		result.Duration = info.duration
+		result.Hooks = info.hooks
+	}
+	return result
+}
+
+// NewCommitResult converts the error returned by Commit into a CommitResult.
+func NewCommitResult(err error) *CommitResult {
 	if err != nil {
 		if commitErr, ok := err.(*CommitError); ok {
 			return &CommitResult{
-				Success: false,
-				Message: commitErr.Message,
-				Details: commitErr.GetDetails(),
+				Success:  false,
+				Type:     commitErr.Type,
+				Message:  commitErr.Message,
+				Details:  commitErr.GetDetails(),
+				Hint:     commitErr.Hint,
+				Error:    commitErr,
+				Duration: commitErr.Duration,
+				Hooks:    commitErr.Hooks,
 			}
 		}
 		return &CommitResult{
@@ -176,3 +672,31 @@ func CommitWithResult(message string) *CommitResult {
 		Message: "Changes committed successfully",
 	}
 }
+
+// ConfigValues returns all git config entries in the given section, keyed by
+// their lowercased name without the section prefix.
+func ConfigValues(section string) (map[string][]string, error) {
+	cmd := command("config", "-z", "--get-regexp", "^"+section+"\\.")
+	var outBuffer bytes.Buffer
+	cmd.Stdout = &outBuffer
+
+	values := make(map[string][]string)
+	if err := cmd.Run(); err != nil {
+		// Exit status 1 means no matching entries
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+			return values, nil
+		}
+		return nil, fmt.Errorf("failed to read git config: %w", err)
+	}
+
+	prefix := strings.ToLower(section) + "."
+	for _, entry := range strings.Split(outBuffer.String(), "\x00") {
+		if entry == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(entry, "\n")
+		key = strings.TrimPrefix(key, prefix)
+		values[key] = append(values[key], value)
+	}
+	return values, nil
+}
@@ -1,9 +1,14 @@
 package git
 
 import (
+	"context"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-git/go-git/v5"
 )
@@ -16,10 +21,11 @@ func TestParseCommitError(t *testing.T) {
 		message  string
 	}{
 		{
-			name:     "hook failure",
+			// Hook failures are detected from git's trace, not its output
+			name:     "hook mentioned in output",
 			output:   "pre-commit hook failed",
-			expected: ErrorTypeHookFailed,
-			message:  "Pre-commit hook failed",
+			expected: ErrorTypeUnknown,
+			message:  "pre-commit hook failed",
 		},
 		{
 			name:     "no changes",
@@ -27,12 +33,42 @@ func TestParseCommitError(t *testing.T) {
 			expected: ErrorTypeNoChanges,
 			message:  "No changes to commit",
 		},
+		{
+			name:     "unstaged changes",
+			output:   "On branch main\nChanges not staged for commit:\n\nno changes added to commit",
+			expected: ErrorTypeNoChanges,
+			message:  "No changes to commit",
+		},
+		{
+			name:     "untracked files",
+			output:   "On branch main\nUntracked files:\n\nnothing added to commit but untracked files present",
+			expected: ErrorTypeNoChanges,
+			message:  "No changes to commit",
+		},
 		{
 			name:     "merge conflict",
 			output:   "fix conflicts then run git commit",
 			expected: ErrorTypeMergeConflict,
 			message:  "Merge conflicts need to be resolved",
 		},
+		{
+			name:     "identity missing",
+			output:   "Author identity unknown\n\n*** Please tell me who you are.",
+			expected: ErrorTypeIdentityMissing,
+			message:  "Your git identity is not configured",
+		},
+		{
+			name:     "index locked",
+			output:   "fatal: Unable to create '/repo/.git/index.lock': File exists.",
+			expected: ErrorTypeIndexLocked,
+			message:  "The index is locked by another git process",
+		},
+		{
+			name:     "empty message",
+			output:   "Aborting commit due to empty commit message.",
+			expected: ErrorTypeEmptyMessage,
+			message:  "The commit message is empty after cleanup",
+		},
 		{
 			name:     "not in repo",
 			output:   "not a git repository",
@@ -56,6 +92,9 @@ func TestParseCommitError(t *testing.T) {
 			if err.Message != tt.message {
 				t.Errorf("expected message %q, got %q", tt.message, err.Message)
 			}
+			if err.Hint != tt.expected.Hint() {
+				t.Errorf("expected hint %q, got %q", tt.expected.Hint(), err.Hint)
+			}
 		})
 	}
 }
@@ -122,7 +161,7 @@ func TestCommitSuccess(t *testing.T) {
 	}
 
 	// Test successful commit
-	err = Commit("feat: add test file")
+	err = Commit(context.Background(), "feat: add test file", CommitOptions{})
 	if err != nil {
 		t.Errorf("expected no error, got %v", err)
 	}
@@ -154,7 +193,7 @@ func TestCommitNoChanges(t *testing.T) {
 	}
 
 	// Test commit with no changes
-	err = Commit("feat: test commit")
+	err = Commit(context.Background(), "feat: test commit", CommitOptions{})
 	if err == nil {
 		t.Error("expected error for no changes")
 		return
@@ -170,3 +209,350 @@ func TestCommitNoChanges(t *testing.T) {
 		t.Errorf("expected error type %v, got %v. Output: %q", ErrorTypeNoChanges, commitErr.Type, commitErr.Output)
 	}
 }
+
+func TestValidateCommitArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr bool
+	}{
+		{name: "empty", args: nil},
+		{name: "allowed flags", args: []string{"--no-verify", "--allow-empty", "--date=now", "-s"}},
+		{name: "short message", args: []string{"-m", "x"}, wantErr: true},
+		{name: "attached message", args: []string{"-mx"}, wantErr: true},
+		{name: "message in cluster", args: []string{"-am"}, wantErr: true},
+		{name: "file", args: []string{"-F", "msg.txt"}, wantErr: true},
+		{name: "long file", args: []string{"--file=msg.txt"}, wantErr: true},
+		{name: "edit", args: []string{"-e"}, wantErr: true},
+		{name: "long edit", args: []string{"--edit"}, wantErr: true},
+		{name: "abbreviated message", args: []string{"--mess=injected"}, wantErr: true},
+		{name: "abbreviated file", args: []string{"--fil", "msg.txt"}, wantErr: true},
+		{name: "abbreviated edit", args: []string{"--ed"}, wantErr: true},
+		{name: "no edit", args: []string{"--no-edit"}},
+		{name: "attached signing key", args: []string{"-Skeyme"}},
+		{name: "pathspec after separator", args: []string{"--", "-m"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateCommitArgs(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestCommitWithExtraArgs(t *testing.T) {
+	tempDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+
+	os.Chdir(tempDir)
+
+	repo, err := git.PlainInit(".", false)
+	if err != nil {
+		t.Fatalf("failed to init git repo: %v", err)
+	}
+
+	cfg, err := repo.Config()
+	if err != nil {
+		t.Fatalf("failed to get config: %v", err)
+	}
+	cfg.User.Name = "Test User"
+	cfg.User.Email = "test@example.com"
+	if err := repo.SetConfig(cfg); err != nil {
+		t.Fatalf("failed to set config: %v", err)
+	}
+
+	// --allow-empty lets the commit succeed without staged changes
+	if err := Commit(context.Background(), "chore: empty commit", CommitOptions{Args: []string{"--allow-empty"}}); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	if err := Commit(context.Background(), "chore: empty commit", CommitOptions{Args: []string{"-m", "other"}}); err == nil {
+		t.Error("expected error for conflicting argument")
+	}
+}
+
+func TestCommitTimeout(t *testing.T) {
+	tempDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+
+	os.Chdir(tempDir)
+
+	repo, err := git.PlainInit(".", false)
+	if err != nil {
+		t.Fatalf("failed to init git repo: %v", err)
+	}
+	cfg, _ := repo.Config()
+	cfg.User.Name = "Test User"
+	cfg.User.Email = "test@example.com"
+	if err := repo.SetConfig(cfg); err != nil {
+		t.Fatalf("failed to set config: %v", err)
+	}
+
+	// The hook starts a child that keeps the output pipes open
+	hook := "#!/bin/sh\nsleep 30\n"
+	os.MkdirAll(filepath.Join(".git", "hooks"), 0755)
+	if err := os.WriteFile(filepath.Join(".git", "hooks", "pre-commit"), []byte(hook), 0755); err != nil {
+		t.Fatalf("failed to create hook: %v", err)
+	}
+
+	start := time.Now()
+	err = Commit(context.Background(), "feat: slow hook", CommitOptions{
+		Args:    []string{"--allow-empty"},
+		Timeout: 200 * time.Millisecond,
+	})
+	elapsed := time.Since(start)
+
+	commitErr, ok := err.(*CommitError)
+	if !ok {
+		t.Fatalf("expected CommitError, got %T: %v", err, err)
+	}
+	if commitErr.Type != ErrorTypeTimeout {
+		t.Errorf("expected error type %v, got %v", ErrorTypeTimeout, commitErr.Type)
+	}
+	if elapsed > cancelGracePeriod {
+		t.Errorf("expected the hook to be killed with git, took %v", elapsed)
+	}
+	if _, err := os.Stat(filepath.Join(".git", "index.lock")); !os.IsNotExist(err) {
+		t.Error("expected index.lock to be removed")
+	}
+}
+
+func TestCommitCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := contextError(ctx, 0, "")
+	if err.Type != ErrorTypeCancelled {
+		t.Errorf("expected error type %v, got %v", ErrorTypeCancelled, err.Type)
+	}
+}
+
+func TestCommitHookFailure(t *testing.T) {
+	tempDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+
+	os.Chdir(tempDir)
+
+	repo, err := git.PlainInit(".", false)
+	if err != nil {
+		t.Fatalf("failed to init git repo: %v", err)
+	}
+	cfg, _ := repo.Config()
+	cfg.User.Name = "Test User"
+	cfg.User.Email = "test@example.com"
+	if err := repo.SetConfig(cfg); err != nil {
+		t.Fatalf("failed to set config: %v", err)
+	}
+
+	// The hook runs git itself, whose own trace must not be mistaken for
+	// the commit's hooks
+	hook := "#!/bin/sh\ngit status >/dev/null\necho lint output\necho lint error >&2\nexit 3\n"
+	os.MkdirAll(filepath.Join(".git", "hooks"), 0755)
+	if err := os.WriteFile(filepath.Join(".git", "hooks", "commit-msg"), []byte(hook), 0755); err != nil {
+		t.Fatalf("failed to create hook: %v", err)
+	}
+
+	err = Commit(context.Background(), "feat: failing hook", CommitOptions{Args: []string{"--allow-empty"}})
+	commitErr, ok := err.(*CommitError)
+	if !ok {
+		t.Fatalf("expected CommitError, got %T: %v", err, err)
+	}
+
+	if commitErr.Type != ErrorTypeCommitMsgHookFailed {
+		t.Fatalf("expected error type %v, got %v", ErrorTypeCommitMsgHookFailed, commitErr.Type)
+	}
+	if commitErr.Hook != "commit-msg" || commitErr.Message != "Commit-msg hook failed" {
+		t.Errorf("unexpected hook %q with message %q", commitErr.Hook, commitErr.Message)
+	}
+	if commitErr.HookExitCode != 3 {
+		t.Errorf("expected hook exit code 3, got %d", commitErr.HookExitCode)
+	}
+	if commitErr.ExitCode != 1 {
+		t.Errorf("expected git exit code 1, got %d", commitErr.ExitCode)
+	}
+	// Git redirects the output of hooks to its stderr
+	if commitErr.Stdout != "" || commitErr.Stderr != "lint output\nlint error\n" {
+		t.Errorf("unexpected stdout %q and stderr %q", commitErr.Stdout, commitErr.Stderr)
+	}
+	if commitErr.Duration <= 0 || commitErr.HookDuration <= 0 {
+		t.Errorf("expected durations to be recorded, got %v and %v", commitErr.Duration, commitErr.HookDuration)
+	}
+	if len(commitErr.Hooks) != 1 || commitErr.Hooks[0].Name != "commit-msg" || commitErr.Hooks[0].ExitCode != 3 {
+		t.Errorf("expected the commit-msg hook run, got %+v", commitErr.Hooks)
+	}
+}
+
+func TestCommitWithResult(t *testing.T) {
+	tempDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+
+	os.Chdir(tempDir)
+
+	repo, err := git.PlainInit(".", false)
+	if err != nil {
+		t.Fatalf("failed to init git repo: %v", err)
+	}
+	cfg, _ := repo.Config()
+	cfg.User.Name = "Test User"
+	cfg.User.Email = "test@example.com"
+	if err := repo.SetConfig(cfg); err != nil {
+		t.Fatalf("failed to set config: %v", err)
+	}
+
+	result := CommitWithResult(context.Background(), "feat: first", CommitOptions{Args: []string{"--allow-empty"}})
+	if !result.Success {
+		t.Fatalf("expected success, got %+v", result)
+	}
+	head, err := headCommit("")
+	if err != nil {
+		t.Fatalf("failed to resolve HEAD: %v", err)
+	}
+	if result.SHA != head {
+		t.Errorf("expected SHA %q, got %q", head, result.SHA)
+	}
+	if len(result.Warnings) != 0 {
+		t.Errorf("expected no warnings, got %v", result.Warnings)
+	}
+
+	// A dry run creates no commit, so it is not a success
+	if err := os.WriteFile("a.txt", []byte("a"), 0644); err != nil {
+		t.Fatalf("failed to create file: %v", err)
+	}
+	worktree, _ := repo.Worktree()
+	if _, err := worktree.Add("a.txt"); err != nil {
+		t.Fatalf("failed to stage file: %v", err)
+	}
+	result = CommitWithResult(context.Background(), "feat: dry", CommitOptions{Args: []string{"--dry-run"}})
+	if result.Success || result.SHA != "" {
+		t.Errorf("expected failure without SHA, got %+v", result)
+	}
+
+	hook := "#!/bin/sh\necho 'chore: rewritten' > \"$1\"\n"
+	os.MkdirAll(filepath.Join(".git", "hooks"), 0755)
+	if err := os.WriteFile(filepath.Join(".git", "hooks", "commit-msg"), []byte(hook), 0755); err != nil {
+		t.Fatalf("failed to create hook: %v", err)
+	}
+
+	result = CommitWithResult(context.Background(), "feat: second", CommitOptions{Args: []string{"--allow-empty"}})
+	if !result.Success || result.SHA == "" || result.SHA == head {
+		t.Fatalf("expected a new commit, got %+v", result)
+	}
+	if len(result.Warnings) != 1 {
+		t.Errorf("expected a warning about the rewritten message, got %v", result.Warnings)
+	}
+}
+
+func TestCommitWarnings(t *testing.T) {
+	stderr := "hint: something\nwarning: LF will be replaced by CRLF\n  Warning: other\nerror: nope\n"
+	warnings := commitWarnings(stderr)
+	if len(warnings) != 2 || warnings[0] != "warning: LF will be replaced by CRLF" || warnings[1] != "Warning: other" {
+		t.Errorf("unexpected warnings %q", warnings)
+	}
+}
+
+func TestCommitDetachedHead(t *testing.T) {
+	tempDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+
+	os.Chdir(tempDir)
+
+	setupRepo(t,
+		[]string{"commit", "-q", "--allow-empty", "-m", "initial"},
+		[]string{"checkout", "-q", "--detach"},
+	)
+
+	// A detached HEAD is only warned about by default
+	result := CommitWithResult(context.Background(), "feat: detached", CommitOptions{Args: []string{"--allow-empty"}})
+	if !result.Success {
+		t.Fatalf("expected commit on detached HEAD to be allowed, got %+v", result)
+	}
+	if !slices.Contains(result.Warnings, "HEAD is detached, the commit is not on any branch") {
+		t.Errorf("expected a detached HEAD warning, got %q", result.Warnings)
+	}
+
+	opts := CommitOptions{Args: []string{"--allow-empty"}, BlockDetachedHead: true}
+	err := Commit(context.Background(), "feat: detached", opts)
+	commitErr, ok := err.(*CommitError)
+	if !ok || commitErr.Type != ErrorTypeDetachedHead {
+		t.Fatalf("expected detached HEAD error, got %v", err)
+	}
+
+	opts.AllowDetachedHead = true
+	if err := Commit(context.Background(), "feat: detached", opts); err != nil {
+		t.Errorf("expected the confirmed commit on detached HEAD to be allowed, got %v", err)
+	}
+}
+
+func TestCommitOperationInProgress(t *testing.T) {
+	tempDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+
+	os.Chdir(tempDir)
+
+	os.WriteFile("a.txt", []byte("base\n"), 0644)
+	setupRepo(t,
+		[]string{"add", "a.txt"},
+		[]string{"commit", "-q", "-m", "base"},
+		[]string{"checkout", "-q", "-b", "other"},
+	)
+	os.WriteFile("a.txt", []byte("other\n"), 0644)
+	setupRepo(t,
+		[]string{"commit", "-q", "-am", "other"},
+		[]string{"checkout", "-q", "-"},
+	)
+	os.WriteFile("a.txt", []byte("main\n"), 0644)
+	setupRepo(t, []string{"commit", "-q", "-am", "main"})
+
+	// The cherry-pick stops on a conflict
+	exec.Command("git", "cherry-pick", "other").Run()
+
+	err := Commit(context.Background(), "fix: conflict", CommitOptions{})
+	commitErr, ok := err.(*CommitError)
+	if !ok || commitErr.Type != ErrorTypeOperationInProgress {
+		t.Fatalf("expected operation in progress error, got %v", err)
+	}
+	if !strings.Contains(commitErr.Hint, "git cherry-pick --continue") {
+		t.Errorf("expected cherry-pick hint, got %q", commitErr.Hint)
+	}
+}
+
+// setupRepo initializes a repository with a test identity in the current
+// directory, if there is none yet, and runs the given git commands.
+func setupRepo(t *testing.T, commands ...[]string) {
+	t.Helper()
+
+	if _, err := os.Stat(".git"); os.IsNotExist(err) {
+		commands = append([][]string{
+			{"init", "-q"},
+			{"config", "user.name", "Test User"},
+			{"config", "user.email", "test@example.com"},
+		}, commands...)
+	}
+	for _, args := range commands {
+		if output, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("failed to run git %v: %v\n%s", args, err, output)
+		}
+	}
+}
+
+func TestErrorTypeNames(t *testing.T) {
+	for errType := ErrorTypeUnknown; errType <= ErrorTypeCommitMsgHookFailed; errType++ {
+		if _, ok := errorTypeNames[errType]; !ok {
+			t.Errorf("expected a name for error type %d", int(errType))
+		}
+	}
+
+	text, err := ErrorTypeHookFailed.MarshalText()
+	if err != nil || string(text) != "hook_failed" {
+		t.Errorf("expected hook_failed, got %q, %v", text, err)
+	}
+}
@@ -0,0 +1,107 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// headCommit returns the hash of the commit HEAD points to in sub, or "" if
+// the current branch has no commit yet. An empty sub is the repository
+// itself.
+func headCommit(sub string) (string, error) {
+	output, err := commandIn(sub, "rev-parse", "-q", "--verify", "HEAD^{commit}").Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// commitMessage returns the full message of the given commit in sub.
+func commitMessage(sub, sha string) (string, error) {
+	output, err := commandIn(sub, "log", "-1", "--format=%B", sha).Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to read commit message: %w", err)
+	}
+	return string(output), nil
+}
+
+// CommitMessage returns the full message of the given commit.
+func CommitMessage(sha string) (string, error) {
+	return commitMessage("", sha)
+}
+
+// ShortSHA abbreviates a commit hash for display.
+func ShortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
+
+// cleanMessage cleans up message the way git commit -m does.
+func cleanMessage(message string) (string, error) {
+	cmd := command("stripspace")
+	cmd.Stdin = strings.NewReader(message)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to clean up message: %w", err)
+	}
+	return string(output), nil
+}
+
+// hasMessage reports whether the message of the given commit in sub starts
+// with message. Trailers such as those added by --signoff may follow it.
+func hasMessage(sub, sha, message string) bool {
+	actual, err := commitMessage(sub, sha)
+	if err != nil {
+		return false
+	}
+	expected, err := cleanMessage(message)
+	if err != nil {
+		return false
+	}
+	return strings.HasPrefix(strings.TrimSpace(actual), strings.TrimSpace(expected))
+}
+
+// FileStat is a file changed by a commit.
+type FileStat struct {
+	Path       string
+	Insertions int
+	Deletions  int
+	// Binary is set for binary files, which have no line counts.
+	Binary bool
+}
+
+// CommitStats returns the files changed by the given commit, compared with
+// its first parent.
+func CommitStats(sha string) ([]FileStat, error) {
+	output, err := command("show", "--format=", "--numstat", "--no-renames", "-z",
+		"--diff-merges=first-parent", sha).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the files of %s: %w", sha, err)
+	}
+
+	var stats []FileStat
+	for _, entry := range strings.Split(string(output), "\x00") {
+		fields := strings.SplitN(strings.TrimLeft(entry, "\n"), "\t", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		stat := FileStat{Path: fields[2]}
+		if fields[0] == "-" {
+			stat.Binary = true
+		} else {
+			stat.Insertions, _ = strconv.Atoi(fields[0])
+			stat.Deletions, _ = strconv.Atoi(fields[1])
+		}
+		stats = append(stats, stat)
+	}
+	return stats, nil
+}
@@ -0,0 +1,72 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"reflect"
+	"testing"
+)
+
+func TestHeadCommit(t *testing.T) {
+	tempDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+
+	os.Chdir(tempDir)
+
+	if err := exec.Command("git", "init").Run(); err != nil {
+		t.Fatalf("failed to init git repo: %v", err)
+	}
+
+	// An unborn branch has no HEAD commit
+	head, err := headCommit("")
+	if err != nil || head != "" {
+		t.Fatalf("expected no HEAD, got %q, %v", head, err)
+	}
+
+	commit := exec.Command("git", "-c", "user.name=Test", "-c", "user.email=test@example.com",
+		"commit", "--allow-empty", "-m", "feat: test\n\n\nbody  ", "--signoff")
+	if output, err := commit.CombinedOutput(); err != nil {
+		t.Fatalf("failed to commit: %v\n%s", err, output)
+	}
+
+	head, err = headCommit("")
+	if err != nil || head == "" {
+		t.Fatalf("expected HEAD, got %q, %v", head, err)
+	}
+
+	if !hasMessage("", head, "feat: test\n\n\nbody  ") {
+		t.Error("expected the cleaned up message to match despite the sign-off")
+	}
+	if hasMessage("", head, "feat: other") {
+		t.Error("expected a different message not to match")
+	}
+}
+
+func TestCommitStats(t *testing.T) {
+	tempDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+
+	os.Chdir(tempDir)
+	setupRepo(t)
+	os.WriteFile("a.txt", []byte("a\nb\n"), 0644)
+	os.WriteFile("bin.dat", []byte{0, 1, 2}, 0644)
+	setupRepo(t, []string{"add", "."}, []string{"commit", "-q", "-m", "initial"})
+
+	head, _ := headCommit("")
+	stats, err := CommitStats(head)
+	expected := []FileStat{{Path: "a.txt", Insertions: 2}, {Path: "bin.dat", Binary: true}}
+	if err != nil || !reflect.DeepEqual(stats, expected) {
+		t.Errorf("expected %+v, got %+v, %v", expected, stats, err)
+	}
+
+	os.WriteFile("a.txt", []byte("a\nc\n"), 0644)
+	setupRepo(t, []string{"commit", "-q", "-am", "change"})
+	head, _ = headCommit("")
+	stats, err = CommitStats(head)
+	expected = []FileStat{{Path: "a.txt", Insertions: 1, Deletions: 1}}
+	if err != nil || !reflect.DeepEqual(stats, expected) {
+		t.Errorf("expected %+v, got %+v, %v", expected, stats, err)
+	}
+}
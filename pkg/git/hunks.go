@@ -0,0 +1,288 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Hunk is a single hunk of a unified diff.
+type Hunk struct {
+	OldStart int
+	OldLines int
+	NewStart int
+	NewLines int
+	// Section is the text after the range header, usually the enclosing function.
+	Section string
+	// Lines are the hunk body lines including their ' ', '+', '-' or '\' prefix.
+	Lines []string
+}
+
+// FileDiff is the unified diff of a single file.
+type FileDiff struct {
+	Path string
+	// Header holds the lines preceding the first hunk (diff --git, index, ---, +++).
+	Header []string
+	Hunks  []Hunk
+}
+
+// Header returns the range line of the hunk, such as "@@ -1,3 +1,4 @@".
+func (h Hunk) Header() string {
+	header := fmt.Sprintf("@@ -%d,%d +%d,%d @@", h.OldStart, h.OldLines, h.NewStart, h.NewLines)
+	if h.Section != "" {
+		header += " " + h.Section
+	}
+	return header
+}
+
+// GetUnstagedDiff returns the diff between the index and the working tree for
+// the given path.
+func GetUnstagedDiff(path string) (*FileDiff, error) {
+	cmd := command("diff", "--no-color", "--no-ext-diff", "-U3", "--", path)
+	var outBuffer bytes.Buffer
+	cmd.Stdout = &outBuffer
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("failed to get diff of %s: %w", path, err)
+	}
+
+	return parseFileDiff(path, outBuffer.String())
+}
+
+func parseFileDiff(path, output string) (*FileDiff, error) {
+	diff := &FileDiff{Path: path}
+
+	lines := strings.Split(strings.TrimSuffix(output, "\n"), "\n")
+	for _, line := range lines {
+		if strings.HasPrefix(line, "@@") {
+			hunk, err := parseHunkHeader(line)
+			if err != nil {
+				return nil, err
+			}
+			diff.Hunks = append(diff.Hunks, hunk)
+			continue
+		}
+
+		if len(diff.Hunks) == 0 {
+			if line != "" {
+				diff.Header = append(diff.Header, line)
+			}
+			continue
+		}
+
+		last := &diff.Hunks[len(diff.Hunks)-1]
+		last.Lines = append(last.Lines, line)
+	}
+
+	return diff, nil
+}
+
+// parseHunkHeader parses a line such as "@@ -1,3 +1,4 @@ func main()".
+func parseHunkHeader(line string) (Hunk, error) {
+	var hunk Hunk
+
+	fields := strings.SplitN(line, " ", 5)
+	if len(fields) < 4 || fields[0] != "@@" || fields[3] != "@@" {
+		return hunk, fmt.Errorf("invalid hunk header %q", line)
+	}
+
+	var err error
+	if hunk.OldStart, hunk.OldLines, err = parseRange(fields[1], "-"); err != nil {
+		return hunk, fmt.Errorf("invalid hunk header %q: %w", line, err)
+	}
+	if hunk.NewStart, hunk.NewLines, err = parseRange(fields[2], "+"); err != nil {
+		return hunk, fmt.Errorf("invalid hunk header %q: %w", line, err)
+	}
+	if len(fields) == 5 {
+		hunk.Section = fields[4]
+	}
+
+	return hunk, nil
+}
+
+// parseRange parses a range such as "-1,3"; a missing count means one line.
+func parseRange(r, prefix string) (start, count int, err error) {
+	r = strings.TrimPrefix(r, prefix)
+	startStr, countStr, hasCount := strings.Cut(r, ",")
+
+	if start, err = strconv.Atoi(startStr); err != nil {
+		return 0, 0, err
+	}
+	count = 1
+	if hasCount {
+		if count, err = strconv.Atoi(countStr); err != nil {
+			return 0, 0, err
+		}
+	}
+	return start, count, nil
+}
+
+// Split breaks the hunk into smaller hunks, one per group of changed lines
+// separated by context. Context lines between two groups are shared by both
+// resulting hunks. A hunk with a single group of changes is returned as is.
+func (h Hunk) Split() []Hunk {
+	// Mark each line as part of a change group or as context. A "\ No newline"
+	// marker belongs to the line it follows.
+	isChange := make([]bool, len(h.Lines))
+	for i, line := range h.Lines {
+		switch {
+		case strings.HasPrefix(line, "+"), strings.HasPrefix(line, "-"):
+			isChange[i] = true
+		case strings.HasPrefix(line, `\`) && i > 0:
+			isChange[i] = isChange[i-1]
+		}
+	}
+
+	// Find the [start, end) bounds of every change group
+	var groups [][2]int
+	for i := 0; i < len(h.Lines); i++ {
+		if !isChange[i] {
+			continue
+		}
+		start := i
+		for i < len(h.Lines) && isChange[i] {
+			i++
+		}
+		groups = append(groups, [2]int{start, i})
+	}
+
+	if len(groups) < 2 {
+		return []Hunk{h}
+	}
+
+	// Line numbers before consuming each line
+	oldNo := make([]int, len(h.Lines)+1)
+	newNo := make([]int, len(h.Lines)+1)
+	oldNo[0], newNo[0] = h.OldStart, h.NewStart
+	for i, line := range h.Lines {
+		oldNo[i+1], newNo[i+1] = oldNo[i], newNo[i]
+		switch {
+		case strings.HasPrefix(line, "-"):
+			oldNo[i+1]++
+		case strings.HasPrefix(line, "+"):
+			newNo[i+1]++
+		case strings.HasPrefix(line, `\`):
+		default:
+			oldNo[i+1]++
+			newNo[i+1]++
+		}
+	}
+
+	hunks := make([]Hunk, 0, len(groups))
+	for i := range groups {
+		from := 0
+		if i > 0 {
+			from = groups[i-1][1]
+		}
+		to := len(h.Lines)
+		if i < len(groups)-1 {
+			to = groups[i+1][0]
+		}
+
+		sub := Hunk{
+			OldStart: oldNo[from],
+			NewStart: newNo[from],
+			Lines:    append([]string(nil), h.Lines[from:to]...),
+		}
+		if i == 0 {
+			sub.Section = h.Section
+		}
+		sub.OldLines, sub.NewLines = countLines(sub.Lines)
+		hunks = append(hunks, sub)
+	}
+
+	return hunks
+}
+
+// countLines returns the number of lines a hunk body spans in the old and
+// the new version of the file.
+func countLines(lines []string) (oldLines, newLines int) {
+	for _, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "-"):
+			oldLines++
+		case strings.HasPrefix(line, "+"):
+			newLines++
+		case strings.HasPrefix(line, `\`):
+		default:
+			oldLines++
+			newLines++
+		}
+	}
+	return oldLines, newLines
+}
+
+// mergeHunks joins hunks that overlap in the old file, as happens with hunks
+// produced by Split that share context lines. Hunks must be sorted.
+func mergeHunks(hunks []Hunk) []Hunk {
+	var merged []Hunk
+	for _, hunk := range hunks {
+		if len(merged) == 0 {
+			merged = append(merged, hunk)
+			continue
+		}
+
+		last := &merged[len(merged)-1]
+		overlap := last.OldStart + last.OldLines - hunk.OldStart
+		if overlap < 0 {
+			merged = append(merged, hunk)
+			continue
+		}
+
+		// Skip the shared context lines at the start of the next hunk
+		skip := 0
+		for skip < len(hunk.Lines) && overlap > 0 {
+			if !strings.HasPrefix(hunk.Lines[skip], "+") && !strings.HasPrefix(hunk.Lines[skip], `\`) {
+				overlap--
+			}
+			skip++
+		}
+		last.Lines = append(last.Lines, hunk.Lines[skip:]...)
+		last.OldLines, last.NewLines = countLines(last.Lines)
+	}
+	return merged
+}
+
+// Patch returns a patch for the file containing only the given hunks, which
+// must be sorted by position. New line numbers are recomputed to account
+// for left-out hunks.
+func (d *FileDiff) Patch(hunks []Hunk) string {
+	var b strings.Builder
+	for _, line := range d.Header {
+		b.WriteString(line + "\n")
+	}
+
+	offset := 0
+	for _, hunk := range mergeHunks(hunks) {
+		hunk.NewStart = hunk.OldStart + offset
+		if hunk.OldLines == 0 {
+			hunk.NewStart++
+		}
+		if hunk.NewLines == 0 {
+			hunk.NewStart--
+		}
+		offset += hunk.NewLines - hunk.OldLines
+
+		b.WriteString(hunk.Header() + "\n")
+		for _, line := range hunk.Lines {
+			b.WriteString(line + "\n")
+		}
+	}
+	return b.String()
+}
+
+// StageHunks applies the given hunks of the diff to the index.
+func StageHunks(diff *FileDiff, hunks []Hunk) error {
+	if len(hunks) == 0 {
+		return nil
+	}
+
+	cmd := command("apply", "--cached", "--recount", "-")
+	cmd.Stdin = strings.NewReader(diff.Patch(hunks))
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to stage hunks of %s: %s: %w", diff.Path, strings.TrimSpace(string(output)), err)
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+const sampleDiff = `diff --git a/file.txt b/file.txt
+index 1111111..2222222 100644
+--- a/file.txt
++++ b/file.txt
+@@ -1,9 +1,9 @@ header
+ one
+-two
++TWO
+ three
+ four
+ five
+ six
+ seven
+-eight
++EIGHT
+ nine
+`
+
+func TestParseFileDiff(t *testing.T) {
+	diff, err := parseFileDiff("file.txt", sampleDiff)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(diff.Header) != 4 {
+		t.Errorf("expected 4 header lines, got %d", len(diff.Header))
+	}
+	if len(diff.Hunks) != 1 {
+		t.Fatalf("expected 1 hunk, got %d", len(diff.Hunks))
+	}
+
+	hunk := diff.Hunks[0]
+	if hunk.OldStart != 1 || hunk.OldLines != 9 || hunk.NewStart != 1 || hunk.NewLines != 9 {
+		t.Errorf("unexpected hunk range %s", hunk.Header())
+	}
+	if hunk.Section != "header" {
+		t.Errorf("expected section 'header', got %q", hunk.Section)
+	}
+	if len(hunk.Lines) != 11 {
+		t.Errorf("expected 11 hunk lines, got %d", len(hunk.Lines))
+	}
+}
+
+func TestParseHunkHeaderWithoutCount(t *testing.T) {
+	hunk, err := parseHunkHeader("@@ -3 +3,2 @@")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if hunk.OldStart != 3 || hunk.OldLines != 1 || hunk.NewStart != 3 || hunk.NewLines != 2 {
+		t.Errorf("unexpected hunk range %s", hunk.Header())
+	}
+}
+
+func TestHunkSplit(t *testing.T) {
+	diff, _ := parseFileDiff("file.txt", sampleDiff)
+	hunks := diff.Hunks[0].Split()
+
+	if len(hunks) != 2 {
+		t.Fatalf("expected 2 hunks, got %d", len(hunks))
+	}
+
+	if hunks[0].Header() != "@@ -1,7 +1,7 @@ header" {
+		t.Errorf("unexpected first hunk header %q", hunks[0].Header())
+	}
+	if hunks[1].Header() != "@@ -3,7 +3,7 @@" {
+		t.Errorf("unexpected second hunk header %q", hunks[1].Header())
+	}
+
+	// A hunk with a single change group cannot be split
+	if len(hunks[0].Split()) != 1 {
+		t.Error("expected single-group hunk to stay whole")
+	}
+}
+
+func TestPatchMergesSplitHunks(t *testing.T) {
+	diff, _ := parseFileDiff("file.txt", sampleDiff)
+	hunks := diff.Hunks[0].Split()
+
+	patch := diff.Patch(hunks)
+	if strings.Count(patch, "@@ -") != 1 {
+		t.Errorf("expected overlapping hunks to be merged, got:\n%s", patch)
+	}
+	if !strings.Contains(patch, "@@ -1,9 +1,9 @@") {
+		t.Errorf("expected merged hunk to span the whole change, got:\n%s", patch)
+	}
+}
+
+func TestStageHunks(t *testing.T) {
+	tempDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+
+	os.Chdir(tempDir)
+	if err := exec.Command("git", "init").Run(); err != nil {
+		t.Fatalf("failed to init git repo: %v", err)
+	}
+
+	original := "one\ntwo\nthree\nfour\nfive\nsix\nseven\neight\nnine\n"
+	if err := os.WriteFile("file.txt", []byte(original), 0644); err != nil {
+		t.Fatalf("failed to create file: %v", err)
+	}
+	if err := StageFiles([]string{"file.txt"}); err != nil {
+		t.Fatalf("failed to stage file: %v", err)
+	}
+
+	modified := strings.Replace(strings.Replace(original, "two", "TWO", 1), "eight", "EIGHT", 1)
+	if err := os.WriteFile("file.txt", []byte(modified), 0644); err != nil {
+		t.Fatalf("failed to modify file: %v", err)
+	}
+
+	diff, err := GetUnstagedDiff("file.txt")
+	if err != nil {
+		t.Fatalf("failed to get diff: %v", err)
+	}
+	hunks := diff.Hunks[0].Split()
+
+	// Stage only the second change
+	if err := StageHunks(diff, hunks[1:]); err != nil {
+		t.Fatalf("failed to stage hunk: %v", err)
+	}
+
+	output, err := exec.Command("git", "show", ":file.txt").Output()
+	if err != nil {
+		t.Fatalf("failed to read index: %v", err)
+	}
+	expected := strings.Replace(original, "eight", "EIGHT", 1)
+	if string(output) != expected {
+		t.Errorf("expected index content %q, got %q", expected, string(output))
+	}
+}
+
+func TestSplitFileDiffs(t *testing.T) {
+	output := sampleDiff + "diff --git a/image.png b/image.png\nindex 1111111..2222222 100644\nBinary files a/image.png and b/image.png differ\n"
+
+	chunks := splitFileDiffs(output)
+	if len(chunks) != 2 {
+		t.Fatalf("expected 2 chunks, got %d", len(chunks))
+	}
+
+	if path := diffPath(chunks[0]); path != "file.txt" {
+		t.Errorf("expected path 'file.txt', got %q", path)
+	}
+	if path := diffPath(chunks[1]); path != "image.png" {
+		t.Errorf("expected path 'image.png', got %q", path)
+	}
+}
+
+func TestGetStagedDiff(t *testing.T) {
+	tempDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+
+	os.Chdir(tempDir)
+	if err := exec.Command("git", "init").Run(); err != nil {
+		t.Fatalf("failed to init git repo: %v", err)
+	}
+	for _, name := range []string{"a.txt", "b.txt"} {
+		if err := os.WriteFile(name, []byte(name+"\n"), 0644); err != nil {
+			t.Fatalf("failed to create file: %v", err)
+		}
+	}
+	if err := StageFiles([]string{"a.txt", "b.txt"}); err != nil {
+		t.Fatalf("failed to stage files: %v", err)
+	}
+
+	diff, err := GetStagedDiff()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(diff.Stat, "2 files changed") {
+		t.Errorf("expected diffstat to mention 2 files, got %q", diff.Stat)
+	}
+	if len(diff.Files) != 2 || diff.Files[0].Path != "a.txt" || diff.Files[1].Path != "b.txt" {
+		t.Errorf("unexpected files %+v", diff.Files)
+	}
+}
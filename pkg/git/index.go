@@ -0,0 +1,26 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+)
+
+// WriteTree stores the current index as a tree object and returns its hash,
+// so that the index can later be restored with RestoreIndex.
+func WriteTree() (string, error) {
+	output, err := command("write-tree").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to write index tree: %w", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// RestoreIndex resets the index entries of the given paths to their state in
+// tree, leaving the working tree untouched. Paths missing from tree are
+// removed from the index.
+func RestoreIndex(tree string, paths []string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+	return runGit("failed to restore index", append([]string{"reset", "-q", tree, "--"}, paths...)...)
+}
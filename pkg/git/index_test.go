@@ -0,0 +1,46 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+)
+
+func TestWriteTreeAndRestoreIndex(t *testing.T) {
+	tempDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+
+	os.Chdir(tempDir)
+	if err := exec.Command("git", "init").Run(); err != nil {
+		t.Fatalf("failed to init git repo: %v", err)
+	}
+	for _, name := range []string{"a.txt", "b.txt"} {
+		if err := os.WriteFile(name, []byte(name), 0644); err != nil {
+			t.Fatalf("failed to create file: %v", err)
+		}
+	}
+	if err := StageFiles([]string{"a.txt", "b.txt"}); err != nil {
+		t.Fatalf("failed to stage files: %v", err)
+	}
+
+	tree, err := WriteTree()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tree) < 40 {
+		t.Errorf("expected a tree hash, got %q", tree)
+	}
+
+	if err := UnstageFiles([]string{"a.txt", "b.txt"}); err != nil {
+		t.Fatalf("failed to unstage files: %v", err)
+	}
+	if err := RestoreIndex(tree, []string{"b.txt"}); err != nil {
+		t.Fatalf("failed to restore index: %v", err)
+	}
+
+	staged, _ := GetStagedFiles()
+	if len(staged) != 1 || staged[0] != "b.txt" {
+		t.Errorf("expected only b.txt to be restored, got %v", staged)
+	}
+}
@@ -0,0 +1,57 @@
+package git
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LogEntry is a commit listed by Log.
+type LogEntry struct {
+	SHA         string
+	AuthorName  string
+	AuthorEmail string
+	// Date is the author date.
+	Date    time.Time
+	Message string
+}
+
+// logFields is the number of NUL-separated fields of each commit in the
+// output of Log.
+const logFields = 5
+
+// Log returns up to limit commits reachable from HEAD, most recent first. A
+// limit of zero lists every commit. A branch without commits has none.
+func Log(limit int) ([]LogEntry, error) {
+	head, err := headCommit("")
+	if err != nil || head == "" {
+		return nil, err
+	}
+
+	args := []string{"log", "-z", "--format=%H%x00%an%x00%ae%x00%aI%x00%B"}
+	if limit > 0 {
+		args = append(args, "-n", strconv.Itoa(limit))
+	}
+	output, err := command(args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list commits: %w", err)
+	}
+
+	fields := strings.Split(strings.TrimSuffix(string(output), "\x00"), "\x00")
+	entries := make([]LogEntry, 0, len(fields)/logFields)
+	for i := 0; i+logFields <= len(fields); i += logFields {
+		date, err := time.Parse(time.RFC3339, fields[i+3])
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the date of %s: %w", fields[i], err)
+		}
+		entries = append(entries, LogEntry{
+			SHA:         fields[i],
+			AuthorName:  fields[i+1],
+			AuthorEmail: fields[i+2],
+			Date:        date,
+			Message:     strings.TrimSpace(fields[i+4]),
+		})
+	}
+	return entries, nil
+}
@@ -0,0 +1,49 @@
+package git
+
+import (
+	"os"
+	"testing"
+)
+
+func TestLog(t *testing.T) {
+	tempDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+
+	os.Chdir(tempDir)
+	setupRepo(t)
+
+	if entries, err := Log(0); err != nil || entries != nil {
+		t.Errorf("expected no commits, got %v, %v", entries, err)
+	}
+
+	setupRepo(t,
+		[]string{"commit", "-q", "--allow-empty", "-m", "feat: first"},
+		[]string{"commit", "-q", "--allow-empty", "-m", "fix(ui): second\n\nBody.\n\nRefs: #1",
+			"--author", "Ann Other <ann@example.com>", "--date", "2024-03-01T10:00:00+01:00"},
+	)
+
+	entries, err := Log(0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 commits, got %d", len(entries))
+	}
+
+	latest := entries[0]
+	if latest.Message != "fix(ui): second\n\nBody.\n\nRefs: #1" || latest.AuthorName != "Ann Other" ||
+		latest.AuthorEmail != "ann@example.com" || len(latest.SHA) != 40 {
+		t.Errorf("unexpected entry %+v", latest)
+	}
+	if latest.Date.Format("2006-01-02 15:04") != "2024-03-01 10:00" {
+		t.Errorf("expected the author date, got %v", latest.Date)
+	}
+	if entries[1].Message != "feat: first" {
+		t.Errorf("expected the first commit last, got %q", entries[1].Message)
+	}
+
+	if entries, err := Log(1); err != nil || len(entries) != 1 {
+		t.Errorf("expected 1 commit, got %d, %v", len(entries), err)
+	}
+}
@@ -0,0 +1,21 @@
+//go:build !unix
+
+package git
+
+import "os/exec"
+
+// processGroup stops a command on platforms without process groups, where
+// only git itself can be killed.
+type processGroup struct {
+	cmd *exec.Cmd
+}
+
+func newProcessGroup(cmd *exec.Cmd) *processGroup {
+	return &processGroup{cmd: cmd}
+}
+
+func (g *processGroup) terminate() error {
+	return g.cmd.Process.Kill()
+}
+
+func (g *processGroup) stop() {}
@@ -0,0 +1,38 @@
+//go:build unix
+
+package git
+
+import (
+	"os/exec"
+	"syscall"
+	"time"
+)
+
+// processGroup runs a command in its own process group, so that the hooks it
+// starts can be stopped together with it.
+type processGroup struct {
+	cmd  *exec.Cmd
+	kill *time.Timer
+}
+
+func newProcessGroup(cmd *exec.Cmd) *processGroup {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	return &processGroup{cmd: cmd}
+}
+
+// terminate asks every process of the group to exit, giving git the chance
+// to remove its lock files, and kills them after cancelGracePeriod.
+func (g *processGroup) terminate() error {
+	pgid := -g.cmd.Process.Pid
+	g.kill = time.AfterFunc(cancelGracePeriod, func() {
+		_ = syscall.Kill(pgid, syscall.SIGKILL)
+	})
+	return syscall.Kill(pgid, syscall.SIGTERM)
+}
+
+// stop cancels the pending kill once the command has been waited for.
+func (g *processGroup) stop() {
+	if g.kill != nil {
+		g.kill.Stop()
+	}
+}
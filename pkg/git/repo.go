@@ -0,0 +1,207 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+)
+
+// staleLockAge is how old the index lock must be before it is considered
+// left behind by a git process that died, rather than held by a running one.
+const staleLockAge = 30 * time.Second
+
+// GitPath returns the path of name inside the git directory, taking linked
+// worktrees into account.
+func GitPath(name string) (string, error) {
+	output, err := command("rev-parse", "--git-path", name).Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate %s: %w", name, err)
+	}
+	return resolvePath(strings.TrimSpace(string(output))), nil
+}
+
+// gitPathExists reports whether name exists inside the git directory.
+func gitPathExists(name string) bool {
+	path, err := GitPath(name)
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(path)
+	return err == nil
+}
+
+// isDetachedHead reports whether HEAD points to a commit instead of a branch.
+func isDetachedHead() (bool, error) {
+	err := command("symbolic-ref", "-q", "HEAD").Run()
+	if err == nil {
+		return false, nil
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		return true, nil
+	}
+	return false, fmt.Errorf("failed to resolve HEAD: %w", err)
+}
+
+// CurrentBranch returns the short name of the branch HEAD is on, or "" if
+// HEAD is detached.
+func CurrentBranch() (string, error) {
+	output, err := command("symbolic-ref", "-q", "--short", "HEAD").Output()
+	if err == nil {
+		return strings.TrimSpace(string(output)), nil
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		return "", nil
+	}
+	return "", fmt.Errorf("failed to resolve HEAD: %w", err)
+}
+
+// UpstreamStatus compares the current branch with its upstream branch.
+type UpstreamStatus struct {
+	// Name is the upstream branch, such as "origin/main".
+	Name string
+	// Ahead and Behind count the commits only on the current branch and only
+	// on the upstream branch.
+	Ahead  int
+	Behind int
+}
+
+// Upstream compares the current branch with its upstream branch. It returns
+// nil if HEAD is detached or the branch has no upstream.
+func Upstream() (*UpstreamStatus, error) {
+	output, err := command("rev-parse", "-q", "--abbrev-ref", "--symbolic-full-name", "@{upstream}").Output()
+	if err != nil {
+		return nil, nil
+	}
+	status := &UpstreamStatus{Name: strings.TrimSpace(string(output))}
+
+	output, err = command("rev-list", "--left-right", "--count", "HEAD...@{upstream}").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare with %s: %w", status.Name, err)
+	}
+	if _, err := fmt.Sscan(string(output), &status.Ahead, &status.Behind); err != nil {
+		return nil, fmt.Errorf("failed to compare with %s: %w", status.Name, err)
+	}
+	return status, nil
+}
+
+// BranchExists reports whether a local branch with the given name exists.
+func BranchExists(name string) bool {
+	return command("show-ref", "--verify", "--quiet", "refs/heads/"+name).Run() == nil
+}
+
+// CreateBranch creates a branch at HEAD and switches to it, keeping the
+// staged and unstaged changes.
+func CreateBranch(name string) error {
+	return runGit("failed to create branch", "switch", "-q", "-c", name)
+}
+
+// OperationInProgress returns the git command whose operation is in
+// progress, "rebase", "cherry-pick" or "merge", or "" if there is none.
+func OperationInProgress() string {
+	switch {
+	case gitPathExists("rebase-merge"), gitPathExists("rebase-apply"):
+		return "rebase"
+	case gitPathExists("CHERRY_PICK_HEAD"):
+		return "cherry-pick"
+	case gitPathExists("MERGE_HEAD"):
+		return "merge"
+	}
+	return ""
+}
+
+// ConflictedFiles returns the paths with unresolved conflicts.
+func ConflictedFiles() ([]string, error) {
+	output, err := command("diff", "--name-only", "--diff-filter=U", "-z").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list conflicted files: %w", err)
+	}
+
+	var files []string
+	for _, file := range strings.Split(string(output), "\x00") {
+		if file != "" && !slices.Contains(files, file) {
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
+
+// OperationMessage returns the message git prepared for the operation in
+// progress from MERGE_MSG, without comment lines, or "" if there is none.
+func OperationMessage() (string, error) {
+	path, err := GitPath("MERGE_MSG")
+	if err != nil {
+		return "", err
+	}
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read MERGE_MSG: %w", err)
+	}
+
+	var lines []string
+	for _, line := range strings.Split(string(content), "\n") {
+		if !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n")), nil
+}
+
+// IsIndexLockStale reports whether the index lock exists and is old enough
+// that no running git process is likely to hold it.
+func IsIndexLockStale() (bool, error) {
+	path, err := GitPath("index.lock")
+	if err != nil {
+		return false, err
+	}
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return time.Since(info.ModTime()) > staleLockAge, nil
+}
+
+// RemoveIndexLock removes the index lock left behind by a git process.
+func RemoveIndexLock() error {
+	path, err := GitPath("index.lock")
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove index lock: %w", err)
+	}
+	return nil
+}
+
+// Identity returns the user.name and user.email configured for sub, which
+// are empty if unset. An empty sub is the repository itself.
+func Identity(sub string) (name, email string) {
+	get := func(key string) string {
+		output, err := commandIn(sub, "config", "--get", key).Output()
+		if err != nil {
+			return ""
+		}
+		return strings.TrimSpace(string(output))
+	}
+	return get("user.name"), get("user.email")
+}
+
+// SetIdentity sets user.name and user.email for the repository in sub only,
+// such as a submodule. An empty sub is the repository itself.
+func SetIdentity(sub, name, email string) error {
+	if err := runGitIn(sub, "failed to set user.name", "config", "--local", "user.name", name); err != nil {
+		return err
+	}
+	return runGitIn(sub, "failed to set user.email", "config", "--local", "user.email", email)
+}
@@ -0,0 +1,224 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRepoState(t *testing.T) {
+	tempDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+
+	os.Chdir(tempDir)
+
+	commands := [][]string{
+		{"git", "init", "-q"},
+		{"git", "config", "user.name", "Test User"},
+		{"git", "config", "user.email", "test@example.com"},
+		{"git", "commit", "-q", "--allow-empty", "-m", "initial"},
+	}
+	for _, args := range commands {
+		if err := exec.Command(args[0], args[1:]...).Run(); err != nil {
+			t.Fatalf("failed to run %v: %v", args, err)
+		}
+	}
+
+	if detached, err := isDetachedHead(); err != nil || detached {
+		t.Errorf("expected HEAD on a branch, got %v, %v", detached, err)
+	}
+	if op := OperationInProgress(); op != "" {
+		t.Errorf("expected no operation in progress, got %q", op)
+	}
+
+	if err := exec.Command("git", "checkout", "-q", "--detach").Run(); err != nil {
+		t.Fatalf("failed to detach HEAD: %v", err)
+	}
+	if detached, err := isDetachedHead(); err != nil || !detached {
+		t.Errorf("expected detached HEAD, got %v, %v", detached, err)
+	}
+
+	if err := os.Mkdir(filepath.Join(".git", "rebase-merge"), 0755); err != nil {
+		t.Fatalf("failed to fake a rebase: %v", err)
+	}
+	if op := OperationInProgress(); op != "rebase" {
+		t.Errorf("expected rebase in progress, got %q", op)
+	}
+}
+
+func TestIndexLock(t *testing.T) {
+	tempDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+
+	os.Chdir(tempDir)
+
+	if err := exec.Command("git", "init", "-q").Run(); err != nil {
+		t.Fatalf("failed to init git repo: %v", err)
+	}
+
+	if stale, err := IsIndexLockStale(); err != nil || stale {
+		t.Errorf("expected no stale lock, got %v, %v", stale, err)
+	}
+
+	lock := filepath.Join(".git", "index.lock")
+	if err := os.WriteFile(lock, nil, 0644); err != nil {
+		t.Fatalf("failed to create lock: %v", err)
+	}
+	if stale, _ := IsIndexLockStale(); stale {
+		t.Error("expected a fresh lock not to be stale")
+	}
+
+	old := time.Now().Add(-2 * staleLockAge)
+	if err := os.Chtimes(lock, old, old); err != nil {
+		t.Fatalf("failed to age lock: %v", err)
+	}
+	if stale, _ := IsIndexLockStale(); !stale {
+		t.Error("expected an old lock to be stale")
+	}
+
+	if err := RemoveIndexLock(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := os.Stat(lock); !os.IsNotExist(err) {
+		t.Error("expected the lock to be removed")
+	}
+}
+
+func TestSetIdentity(t *testing.T) {
+	tempDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+
+	os.Chdir(tempDir)
+
+	if err := exec.Command("git", "init", "-q").Run(); err != nil {
+		t.Fatalf("failed to init git repo: %v", err)
+	}
+
+	if err := SetIdentity("", "Jane Doe", "jane@example.com"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	output, err := exec.Command("git", "config", "--local", "user.email").Output()
+	if err != nil || string(output) != "jane@example.com\n" {
+		t.Errorf("expected local email to be set, got %q, %v", output, err)
+	}
+	if name, email := Identity(""); name != "Jane Doe" || email != "jane@example.com" {
+		t.Errorf("unexpected identity %q <%s>", name, email)
+	}
+}
+
+func TestMergeInProgress(t *testing.T) {
+	tempDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+
+	os.Chdir(tempDir)
+	setupRepo(t)
+	os.WriteFile("a.txt", []byte("base\n"), 0644)
+	setupRepo(t,
+		[]string{"add", "a.txt"},
+		[]string{"commit", "-q", "-m", "initial"},
+		[]string{"checkout", "-q", "-b", "other"},
+	)
+	os.WriteFile("a.txt", []byte("other\n"), 0644)
+	setupRepo(t,
+		[]string{"commit", "-q", "-am", "other"},
+		[]string{"checkout", "-q", "-"},
+	)
+	os.WriteFile("a.txt", []byte("main\n"), 0644)
+	setupRepo(t, []string{"commit", "-q", "-am", "main"})
+
+	// The merge stops on a conflict
+	exec.Command("git", "merge", "other").Run()
+
+	if op := OperationInProgress(); op != "merge" {
+		t.Errorf("expected merge in progress, got %q", op)
+	}
+	if files, err := ConflictedFiles(); err != nil || len(files) != 1 || files[0] != "a.txt" {
+		t.Errorf("expected a.txt conflicted, got %v, %v", files, err)
+	}
+	message, err := OperationMessage()
+	if err != nil || message != "Merge branch 'other'" {
+		t.Errorf("expected the merge message without comments, got %q, %v", message, err)
+	}
+
+	os.WriteFile("a.txt", []byte("resolved\n"), 0644)
+	setupRepo(t, []string{"add", "a.txt"})
+	if files, err := ConflictedFiles(); err != nil || len(files) != 0 {
+		t.Errorf("expected no conflicts after staging, got %v, %v", files, err)
+	}
+}
+
+func TestBranches(t *testing.T) {
+	tempDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+
+	os.Chdir(tempDir)
+	setupRepo(t, []string{"checkout", "-q", "-b", "main"})
+	os.WriteFile("a.txt", []byte("a"), 0644)
+	setupRepo(t, []string{"add", "a.txt"})
+
+	if branch, err := CurrentBranch(); err != nil || branch != "main" {
+		t.Errorf("expected branch main, got %q, %v", branch, err)
+	}
+	if BranchExists("feat/io") {
+		t.Error("expected feat/io not to exist")
+	}
+
+	// Works before the first commit and keeps the staged changes
+	if err := CreateBranch("feat/io"); err != nil {
+		t.Fatalf("failed to create branch: %v", err)
+	}
+	if branch, err := CurrentBranch(); err != nil || branch != "feat/io" {
+		t.Errorf("expected branch feat/io, got %q, %v", branch, err)
+	}
+	if staged, _ := GetStagedFiles(); len(staged) != 1 {
+		t.Errorf("expected a.txt to stay staged, got %v", staged)
+	}
+
+	setupRepo(t, []string{"commit", "-q", "-m", "add a"}, []string{"checkout", "-q", "--detach"})
+	if !BranchExists("feat/io") {
+		t.Error("expected feat/io to exist")
+	}
+	if branch, err := CurrentBranch(); err != nil || branch != "" {
+		t.Errorf("expected no branch on a detached HEAD, got %q, %v", branch, err)
+	}
+}
+
+func TestUpstream(t *testing.T) {
+	tempDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+
+	os.Chdir(tempDir)
+	setupRepo(t, []string{"checkout", "-q", "-b", "main"}, []string{"commit", "-q", "--allow-empty", "-m", "first"})
+
+	if status, err := Upstream(); err != nil || status != nil {
+		t.Errorf("expected no upstream, got %+v, %v", status, err)
+	}
+
+	setupRepo(t,
+		[]string{"branch", "-q", "base"},
+		[]string{"branch", "-q", "--set-upstream-to", "base"},
+		[]string{"commit", "-q", "--allow-empty", "-m", "second"},
+		[]string{"commit", "-q", "--allow-empty", "-m", "third"},
+	)
+	status, err := Upstream()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if status == nil || *status != (UpstreamStatus{Name: "base", Ahead: 2, Behind: 0}) {
+		t.Errorf("expected 2 commits ahead of base, got %+v", status)
+	}
+
+	setupRepo(t, []string{"checkout", "-q", "--detach"})
+	if status, err := Upstream(); err != nil || status != nil {
+		t.Errorf("expected no upstream on a detached HEAD, got %+v, %v", status, err)
+	}
+}
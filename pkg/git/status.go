@@ -0,0 +1,275 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// FileStatus describes a changed path as reported by git status.
+type FileStatus struct {
+	Path string
+	// OrigPath is the source path of a rename or copy.
+	OrigPath string
+	// Index is the status of the path in the index (X in git status --short).
+	Index byte
+	// WorkTree is the status of the path in the working tree (Y in git status --short).
+	WorkTree byte
+}
+
+// IsStaged reports whether the path has changes in the index.
+func (f FileStatus) IsStaged() bool {
+	return f.Index != ' ' && f.Index != '?' && f.Index != '!'
+}
+
+// IsUntracked reports whether the path is not tracked by git.
+func (f FileStatus) IsUntracked() bool {
+	return f.Index == '?'
+}
+
+// HasUnstagedChanges reports whether the working tree differs from the index.
+func (f FileStatus) HasUnstagedChanges() bool {
+	return f.WorkTree != ' '
+}
+
+// Code returns the two-letter status code used by git status --short.
+func (f FileStatus) Code() string {
+	return string([]byte{f.Index, f.WorkTree})
+}
+
+// GetWorkingTreeStatus returns the staged, modified, deleted and untracked
+// paths of the repository.
+func GetWorkingTreeStatus() ([]FileStatus, error) {
+	cmd := command("status", "--porcelain=v1", "-z", "--untracked-files=all")
+	var outBuffer bytes.Buffer
+	cmd.Stdout = &outBuffer
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("failed to get working tree status: %w", err)
+	}
+
+	return parseStatus(outBuffer.String()), nil
+}
+
+func parseStatus(output string) []FileStatus {
+	var files []FileStatus
+
+	entries := strings.Split(output, "\x00")
+	for i := 0; i < len(entries); i++ {
+		entry := entries[i]
+		if len(entry) < 4 {
+			continue
+		}
+
+		file := FileStatus{
+			Index:    entry[0],
+			WorkTree: entry[1],
+			Path:     entry[3:],
+		}
+
+		// Renames and copies are followed by their source path
+		if file.Index == 'R' || file.Index == 'C' || file.WorkTree == 'R' || file.WorkTree == 'C' {
+			if i+1 < len(entries) {
+				i++
+				file.OrigPath = entries[i]
+			}
+		}
+
+		files = append(files, file)
+	}
+
+	return files
+}
+
+// StageFiles adds the current content of the given paths, including
+// deletions, to the index.
+func StageFiles(paths []string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+	return runGit("failed to stage files", append([]string{"add", "-A", "--"}, paths...)...)
+}
+
+// UnstageFiles resets the index entries of the given paths to HEAD, leaving
+// the working tree untouched.
+func UnstageFiles(paths []string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+	return runGit("failed to unstage files", append([]string{"reset", "-q", "--"}, paths...)...)
+}
+
+// HashWorkTreeFiles returns the object hash of the working tree content of
+// each given path of sub, and writes the content to the object database so
+// that StageWorkTreeChanges can diff against it. Paths missing from the
+// working tree are left out. An empty sub is the repository itself.
+func HashWorkTreeFiles(sub string, paths []string) (map[string]string, error) {
+	var existing []string
+	for _, path := range paths {
+		if info, err := os.Stat(filepath.Join(subDir(sub), path)); err == nil && info.Mode().IsRegular() {
+			existing = append(existing, path)
+		}
+	}
+
+	hashes := make(map[string]string, len(existing))
+	if len(existing) == 0 {
+		return hashes, nil
+	}
+
+	cmd := commandIn(sub, append([]string{"hash-object", "-w", "--"}, existing...)...)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash files: %w", err)
+	}
+
+	for i, hash := range strings.Fields(string(output)) {
+		if i < len(existing) {
+			hashes[existing[i]] = hash
+		}
+	}
+	return hashes, nil
+}
+
+// ChangedFiles returns the paths whose working tree content differs from the
+// hashes recorded by HashWorkTreeFiles for sub.
+func ChangedFiles(sub string, before map[string]string) ([]string, error) {
+	paths := make([]string, 0, len(before))
+	for path := range before {
+		paths = append(paths, path)
+	}
+
+	after, err := HashWorkTreeFiles(sub, paths)
+	if err != nil {
+		return nil, err
+	}
+
+	var changed []string
+	for _, path := range paths {
+		if after[path] != before[path] {
+			changed = append(changed, path)
+		}
+	}
+	sort.Strings(changed)
+	return changed, nil
+}
+
+// StageWorkTreeChanges stages the changes made to the working tree of paths
+// of sub since HashWorkTreeFiles recorded before, such as those of a formatter run
+// by a hook. The rest of the staged content is left alone, so changes left
+// unstaged on purpose stay unstaged. If the changes do not apply to the
+// staged content, nothing is staged. Deleted paths are removed from the
+// index.
+func StageWorkTreeChanges(sub string, before map[string]string, paths []string) error {
+	after, err := HashWorkTreeFiles(sub, paths)
+	if err != nil {
+		return err
+	}
+
+	var patch strings.Builder
+	var deleted []string
+	for _, path := range paths {
+		switch {
+		case after[path] == "":
+			deleted = append(deleted, path)
+		case before[path] != "" && after[path] != before[path]:
+			diff, err := commandIn(sub, "diff", "--binary", "--full-index", before[path], after[path]).Output()
+			if err != nil {
+				return fmt.Errorf("failed to diff %s: %w", path, err)
+			}
+			patch.WriteString(blobPatch(path, string(diff)))
+		}
+	}
+
+	if patch.Len() > 0 {
+		cmd := commandIn(sub, "apply", "--cached")
+		cmd.Stdin = strings.NewReader(patch.String())
+		if output, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("the changes do not apply to the staged content: %s",
+				strings.Join(strings.Fields(string(output)), " "))
+		}
+	}
+	if len(deleted) > 0 {
+		return runGitIn(sub, "failed to stage files", append([]string{"rm", "-q", "--cached", "--"}, deleted...)...)
+	}
+	return nil
+}
+
+// blobPatch turns the diff of two blobs, whose headers name the blobs, into a
+// patch of path.
+func blobPatch(path, diff string) string {
+	oldName, newName := quotePath("a/"+path), quotePath("b/"+path)
+
+	var b strings.Builder
+	header := true
+	for _, line := range strings.SplitAfter(diff, "\n") {
+		if header {
+			switch {
+			case strings.HasPrefix(line, "diff --git "):
+				line = fmt.Sprintf("diff --git %s %s\n", oldName, newName)
+			case strings.HasPrefix(line, "index "):
+				// Without the mode, which would replace the one in the index
+				line = "index " + strings.Fields(line)[1] + "\n"
+			case strings.HasPrefix(line, "--- "):
+				line = "--- " + oldName + "\n"
+			case strings.HasPrefix(line, "+++ "):
+				line = "+++ " + newName + "\n"
+			case strings.HasPrefix(line, "@@"), strings.HasPrefix(line, "GIT binary patch"):
+				header = false
+			}
+		}
+		b.WriteString(line)
+	}
+	return b.String()
+}
+
+// quotePath quotes a path in a patch header the way git does, if it holds
+// characters that need it.
+func quotePath(path string) string {
+	var b strings.Builder
+	quoted := false
+	for i := 0; i < len(path); i++ {
+		c := path[i]
+		switch {
+		case c == '"' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+			quoted = true
+		case c == '\t':
+			b.WriteString(`\t`)
+			quoted = true
+		case c == '\n':
+			b.WriteString(`\n`)
+			quoted = true
+		case c < 0x20 || c >= 0x7f:
+			fmt.Fprintf(&b, `\%03o`, c)
+			quoted = true
+		default:
+			b.WriteByte(c)
+		}
+	}
+	if !quoted {
+		return path
+	}
+	return `"` + b.String() + `"`
+}
+
+// runGit runs a git command and wraps its output into the returned error.
+func runGit(errPrefix string, args ...string) error {
+	return runGitIn("", errPrefix, args...)
+}
+
+// runGitIn is like runGit but runs the command in sub.
+func runGitIn(sub, errPrefix string, args ...string) error {
+	cmd := commandIn(sub, args...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		if msg := strings.TrimSpace(string(output)); msg != "" {
+			return fmt.Errorf("%s: %s: %w", errPrefix, msg, err)
+		}
+		return fmt.Errorf("%s: %w", errPrefix, err)
+	}
+	return nil
+}
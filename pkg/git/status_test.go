@@ -0,0 +1,172 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestParseStatus(t *testing.T) {
+	output := "M  staged.go\x00 M modified.go\x00 D deleted.go\x00?? new.go\x00R  new-name.go\x00old-name.go\x00"
+	files := parseStatus(output)
+
+	if len(files) != 5 {
+		t.Fatalf("expected 5 files, got %d: %+v", len(files), files)
+	}
+
+	tests := []struct {
+		path      string
+		staged    bool
+		unstaged  bool
+		untracked bool
+	}{
+		{path: "staged.go", staged: true},
+		{path: "modified.go", unstaged: true},
+		{path: "deleted.go", unstaged: true},
+		{path: "new.go", unstaged: true, untracked: true},
+		{path: "new-name.go", staged: true},
+	}
+
+	for i, tt := range tests {
+		file := files[i]
+		if file.Path != tt.path {
+			t.Errorf("expected path %q, got %q", tt.path, file.Path)
+		}
+		if file.IsStaged() != tt.staged {
+			t.Errorf("%s: expected staged %v", tt.path, tt.staged)
+		}
+		if file.HasUnstagedChanges() != tt.unstaged {
+			t.Errorf("%s: expected unstaged changes %v", tt.path, tt.unstaged)
+		}
+		if file.IsUntracked() != tt.untracked {
+			t.Errorf("%s: expected untracked %v", tt.path, tt.untracked)
+		}
+	}
+
+	if files[4].OrigPath != "old-name.go" {
+		t.Errorf("expected rename source 'old-name.go', got %q", files[4].OrigPath)
+	}
+}
+
+func TestStageAndUnstageFiles(t *testing.T) {
+	tempDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+
+	os.Chdir(tempDir)
+	if err := exec.Command("git", "init").Run(); err != nil {
+		t.Fatalf("failed to init git repo: %v", err)
+	}
+
+	if err := os.WriteFile("a.txt", []byte("a"), 0644); err != nil {
+		t.Fatalf("failed to create file: %v", err)
+	}
+
+	files, err := GetWorkingTreeStatus()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(files) != 1 || !files[0].IsUntracked() {
+		t.Fatalf("expected one untracked file, got %+v", files)
+	}
+
+	if err := StageFiles([]string{"a.txt"}); err != nil {
+		t.Fatalf("failed to stage: %v", err)
+	}
+	staged, _ := GetStagedFiles()
+	if len(staged) != 1 || staged[0] != "a.txt" {
+		t.Errorf("expected a.txt to be staged, got %v", staged)
+	}
+
+	// Unstaging works on an unborn branch too
+	if err := UnstageFiles([]string{"a.txt"}); err != nil {
+		t.Fatalf("failed to unstage: %v", err)
+	}
+	staged, _ = GetStagedFiles()
+	if len(staged) != 0 {
+		t.Errorf("expected no staged files, got %v", staged)
+	}
+}
+
+func TestStageWorkTreeChanges(t *testing.T) {
+	tempDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+
+	os.Chdir(tempDir)
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	// lines returns the lines 1 to 9, with the given ones replaced
+	lines := func(replaced map[int]string) string {
+		var b strings.Builder
+		for i := 1; i <= 9; i++ {
+			if line, ok := replaced[i]; ok {
+				b.WriteString(line + "\n")
+			} else {
+				fmt.Fprintf(&b, "%d\n", i)
+			}
+		}
+		return b.String()
+	}
+
+	write("a.txt", lines(nil))
+	write("b c.txt", "b\n")
+	setupRepo(t, []string{"add", "."}, []string{"commit", "-q", "-m", "initial"})
+
+	// Line 1 is staged, line 9 is left unstaged on purpose
+	write("a.txt", lines(map[int]string{1: "one"}))
+	write("b c.txt", "bb\n")
+	setupRepo(t, []string{"add", "."})
+	write("a.txt", lines(map[int]string{1: "one", 9: "nine"}))
+
+	paths := []string{"a.txt", "b c.txt"}
+	before, err := HashWorkTreeFiles("", paths)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// A hook changes line 5 and the other file
+	write("a.txt", lines(map[int]string{1: "one", 5: "five", 9: "nine"}))
+	write("b c.txt", "bbb\n")
+	if err := StageWorkTreeChanges("", before, paths); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := lines(map[int]string{1: "one", 5: "five"})
+	if staged, _ := exec.Command("git", "show", ":a.txt").Output(); string(staged) != expected {
+		t.Errorf("expected the hook change alone to be staged, got %q", staged)
+	}
+	if staged, _ := exec.Command("git", "show", ":b c.txt").Output(); string(staged) != "bbb\n" {
+		t.Errorf("expected the other file to be staged, got %q", staged)
+	}
+
+	// A hook change to a line left unstaged does not apply to the index
+	before, _ = HashWorkTreeFiles("", paths)
+	write("a.txt", lines(map[int]string{1: "one", 5: "five", 9: "NINE"}))
+	if err := StageWorkTreeChanges("", before, paths); err == nil {
+		t.Error("expected an error for changes that do not apply")
+	}
+	if staged, _ := exec.Command("git", "show", ":a.txt").Output(); string(staged) != expected {
+		t.Errorf("expected the index to be left alone, got %q", staged)
+	}
+}
+
+func TestQuotePath(t *testing.T) {
+	tests := map[string]string{
+		"a/b c.txt":   "a/b c.txt",
+		`a/"q".txt`:   `"a/\"q\".txt"`,
+		"a/tab\t.txt": `"a/tab\t.txt"`,
+		"a/é.txt":     `"a/\303\251.txt"`,
+	}
+	for path, expected := range tests {
+		if quoted := quotePath(path); quoted != expected {
+			t.Errorf("quotePath(%q): expected %s, got %s", path, expected, quoted)
+		}
+	}
+}
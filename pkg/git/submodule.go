@@ -0,0 +1,92 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// gitlinkMode is the index mode of a submodule entry.
+const gitlinkMode = "160000"
+
+// Submodule is a submodule with changes to commit.
+type Submodule struct {
+	Path string
+	// Staged are the files staged inside the submodule.
+	Staged []string
+	// PointerStaged is set if the index of the repository already records a
+	// new commit for the submodule.
+	PointerStaged bool
+}
+
+// GetSubmodules returns the checked out submodules that have staged changes
+// of their own or whose new commit is staged in the repository.
+func GetSubmodules() ([]Submodule, error) {
+	output, err := command("ls-files", "--stage", "-z").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list submodules: %w", err)
+	}
+
+	var submodules []Submodule
+	for _, entry := range bytes.Split(output, []byte{0}) {
+		// Entries look like "<mode> <object> <stage>\t<path>"
+		info, path, ok := strings.Cut(string(entry), "\t")
+		if !ok || !strings.HasPrefix(info, gitlinkMode+" ") {
+			continue
+		}
+		if _, err := os.Stat(resolvePath(filepath.Join(path, ".git"))); err != nil {
+			// Not checked out, git would run in the repository instead
+			continue
+		}
+
+		staged, err := commandIn(path, "diff", "--cached", "--name-only", "-z").Output()
+		if err != nil {
+			return nil, fmt.Errorf("failed to check submodule %s: %w", path, err)
+		}
+		submodule := Submodule{Path: path}
+		for _, file := range strings.Split(string(staged), "\x00") {
+			if file != "" {
+				submodule.Staged = append(submodule.Staged, file)
+			}
+		}
+
+		// diff --quiet exits with 1 when there are differences
+		submodule.PointerStaged = command("diff", "--cached", "--quiet", "--", path).Run() != nil
+
+		if len(submodule.Staged) > 0 || submodule.PointerStaged {
+			submodules = append(submodules, submodule)
+		}
+	}
+	return submodules, nil
+}
+
+// SubmoduleLog returns the headers of the commits that the staged pointer of
+// the submodule at path adds over the one in HEAD, newest first.
+func SubmoduleLog(path string) ([]string, error) {
+	output, err := command("rev-parse", ":"+path).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve staged commit of %s: %w", path, err)
+	}
+	staged := strings.TrimSpace(string(output))
+
+	args := []string{"log", "--format=%s", "-1", staged}
+	if output, err := command("rev-parse", "-q", "--verify", "HEAD:"+path).Output(); err == nil {
+		// The submodule existed before, list everything since then
+		args = []string{"log", "--format=%s", strings.TrimSpace(string(output)) + ".." + staged}
+	}
+
+	output, err = commandIn(path, args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read log of %s: %w", path, err)
+	}
+
+	var headers []string
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if line != "" {
+			headers = append(headers, line)
+		}
+	}
+	return headers, nil
+}
@@ -0,0 +1,65 @@
+package git
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// setupSubmoduleRepo creates a repository with a committed submodule at lib
+// in the current directory.
+func setupSubmoduleRepo(t *testing.T) {
+	t.Helper()
+
+	upstream := t.TempDir()
+	setupRepo(t,
+		[]string{"init", "-q", upstream},
+		[]string{"-C", upstream, "-c", "user.name=Test User", "-c", "user.email=test@example.com",
+			"commit", "-q", "--allow-empty", "-m", "initial"},
+		[]string{"-c", "protocol.file.allow=always", "submodule", "add", "-q", upstream, "lib"},
+		[]string{"commit", "-q", "-m", "add lib"},
+		[]string{"-C", "lib", "config", "user.name", "Test User"},
+		[]string{"-C", "lib", "config", "user.email", "test@example.com"},
+	)
+}
+
+func TestSubmodules(t *testing.T) {
+	chdirOutside(t)
+	setupSubmoduleRepo(t)
+
+	if submodules, err := GetSubmodules(); err != nil || len(submodules) != 0 {
+		t.Fatalf("expected no submodule changes, got %+v, %v", submodules, err)
+	}
+
+	if err := os.WriteFile(filepath.Join("lib", "a.txt"), []byte("a"), 0644); err != nil {
+		t.Fatalf("failed to create file: %v", err)
+	}
+	setupRepo(t, []string{"-C", "lib", "add", "a.txt"})
+
+	submodules, err := GetSubmodules()
+	expected := []Submodule{{Path: "lib", Staged: []string{"a.txt"}}}
+	if err != nil || !reflect.DeepEqual(submodules, expected) {
+		t.Fatalf("expected %+v, got %+v, %v", expected, submodules, err)
+	}
+
+	err = Commit(context.Background(), "feat: add a", CommitOptions{Dir: "lib"})
+	if err != nil {
+		t.Fatalf("expected commit in the submodule to succeed, got %v", err)
+	}
+	if err := StageFiles([]string{"lib"}); err != nil {
+		t.Fatalf("failed to stage submodule: %v", err)
+	}
+
+	submodules, err = GetSubmodules()
+	expected = []Submodule{{Path: "lib", PointerStaged: true}}
+	if err != nil || !reflect.DeepEqual(submodules, expected) {
+		t.Fatalf("expected %+v, got %+v, %v", expected, submodules, err)
+	}
+
+	headers, err := SubmoduleLog("lib")
+	if err != nil || !reflect.DeepEqual(headers, []string{"feat: add a"}) {
+		t.Errorf("expected the submodule commit, got %v, %v", headers, err)
+	}
+}
@@ -0,0 +1,139 @@
+package git
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// hookTrace records the hooks run by git through its trace2 event stream,
+// which reports the name, exit code and duration of every child process.
+type hookTrace struct {
+	path string
+}
+
+// hookRun is a hook run recorded by a hookTrace.
+type hookRun struct {
+	name     string
+	exitCode int
+	duration time.Duration
+	exited   bool
+}
+
+// HookRun is a hook run by git during a commit.
+type HookRun struct {
+	Name string
+	// ExitCode is the exit status of the hook, or -1 if it did not exit
+	// normally.
+	ExitCode int
+	Duration time.Duration
+}
+
+// hookRuns returns the hooks of the traced git process as HookRuns.
+func (t *hookTrace) hookRuns() []HookRun {
+	var runs []HookRun
+	for _, run := range t.hooks() {
+		exitCode := run.exitCode
+		if !run.exited {
+			exitCode = -1
+		}
+		runs = append(runs, HookRun{Name: run.name, ExitCode: exitCode, Duration: run.duration})
+	}
+	return runs
+}
+
+// traceEvent is the subset of a trace2 event used to follow hooks.
+type traceEvent struct {
+	Event      string   `json:"event"`
+	SID        string   `json:"sid"`
+	ChildID    int      `json:"child_id"`
+	ChildClass string   `json:"child_class"`
+	HookName   string   `json:"hook_name"`
+	Argv       []string `json:"argv"`
+	Code       int      `json:"code"`
+	TRel       float64  `json:"t_rel"`
+}
+
+func newHookTrace() (*hookTrace, error) {
+	file, err := os.CreateTemp("", "git-cc-trace2-*.json")
+	if err != nil {
+		return nil, err
+	}
+	if err := file.Close(); err != nil {
+		return nil, err
+	}
+	return &hookTrace{path: file.Name()}, nil
+}
+
+// env returns the environment variable enabling the trace.
+func (t *hookTrace) env() string {
+	return "GIT_TRACE2_EVENT=" + t.path
+}
+
+func (t *hookTrace) remove() {
+	_ = os.Remove(t.path)
+}
+
+// hooks returns the hooks run by the traced git process, in order. Events of
+// git commands run by the hooks themselves are ignored.
+func (t *hookTrace) hooks() []hookRun {
+	file, err := os.Open(t.path)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	var runs []hookRun
+	index := make(map[int]int)
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var event traceEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			continue
+		}
+		// Nested git processes have a session ID of the form parent/child
+		if strings.Contains(event.SID, "/") {
+			continue
+		}
+
+		switch event.Event {
+		case "child_start":
+			if event.ChildClass != "hook" {
+				continue
+			}
+			name := event.HookName
+			if name == "" && len(event.Argv) > 0 {
+				// Before git 2.36 only the hook path is reported
+				name = filepath.Base(event.Argv[0])
+			}
+			index[event.ChildID] = len(runs)
+			runs = append(runs, hookRun{name: name})
+
+		case "child_exit":
+			i, ok := index[event.ChildID]
+			if !ok {
+				continue
+			}
+			runs[i].exitCode = event.Code
+			runs[i].duration = time.Duration(event.TRel * float64(time.Second))
+			runs[i].exited = true
+		}
+	}
+
+	return runs
+}
+
+// failedHook returns the first hook that exited with a non-zero status.
+func (t *hookTrace) failedHook() *hookRun {
+	for _, run := range t.hooks() {
+		if run.exited && run.exitCode != 0 {
+			return &run
+		}
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package git
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+const sampleTrace = `{"event":"version","sid":"s1","evt":"3"}
+{"event":"child_start","sid":"s1","child_id":0,"child_class":"hook","hook_name":"pre-commit","argv":[".git/hooks/pre-commit"]}
+{"event":"child_start","sid":"s1/s2","child_id":0,"child_class":"hook","hook_name":"nested","argv":["nested"]}
+{"event":"child_exit","sid":"s1/s2","child_id":0,"code":7,"t_rel":0.1}
+{"event":"child_exit","sid":"s1","child_id":0,"code":0,"t_rel":0.25}
+{"event":"child_start","sid":"s1","child_id":1,"child_class":"hook","argv":["/repo/.git/hooks/commit-msg",".git/COMMIT_EDITMSG"]}
+{"event":"child_exit","sid":"s1","child_id":1,"code":3,"t_rel":0.5}
+not json
+`
+
+func TestHookTrace(t *testing.T) {
+	trace, err := newHookTrace()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer trace.remove()
+
+	if err := os.WriteFile(trace.path, []byte(sampleTrace), 0644); err != nil {
+		t.Fatalf("failed to write trace: %v", err)
+	}
+
+	hooks := trace.hooks()
+	if len(hooks) != 2 {
+		t.Fatalf("expected 2 hooks, got %+v", hooks)
+	}
+	if hooks[0].name != "pre-commit" || hooks[0].exitCode != 0 || hooks[0].duration != 250*time.Millisecond {
+		t.Errorf("unexpected first hook %+v", hooks[0])
+	}
+
+	failed := trace.failedHook()
+	if failed == nil {
+		t.Fatal("expected a failed hook")
+	}
+	if failed.name != "commit-msg" || failed.exitCode != 3 {
+		t.Errorf("unexpected failed hook %+v", failed)
+	}
+}
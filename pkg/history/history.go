@@ -0,0 +1,123 @@
+// Package history keeps the messages committed through git-cc in the current
+// repository, so they can be recalled for similar commits.
+package history
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/denysvitali/git-cc/pkg/conventional"
+	"github.com/denysvitali/git-cc/pkg/git"
+)
+
+// fileName is the location of the history inside the git directory.
+const fileName = "git-cc/history.json"
+
+// Entry is a committed message.
+type Entry struct {
+	Header string `json:"header"`
+	Body   string `json:"body,omitempty"`
+}
+
+// NewEntry splits a commit message into its header and the rest of the
+// message, the body followed by the footers.
+func NewEntry(message string) Entry {
+	msg, _ := conventional.Parse(message)
+	header := msg.Header()
+	return Entry{Header: header, Body: strings.TrimSpace(strings.TrimPrefix(msg.String(), header))}
+}
+
+// Parse parses the message of the entry. It returns an error wrapping
+// conventional.ErrNotConventional if the header is not conventional.
+func (e Entry) Parse() (conventional.Message, error) {
+	return conventional.Parse(e.Message())
+}
+
+// Message returns the full commit message of the entry.
+func (e Entry) Message() string {
+	if e.Body == "" {
+		return e.Header
+	}
+	return e.Header + "\n\n" + e.Body
+}
+
+// Path returns the location of the history of the current repository.
+func Path() (string, error) {
+	return git.GitPath(fileName)
+}
+
+// Load returns the recorded messages, most recent first.
+func Load() ([]Entry, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+
+	var entries []Entry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse history %s: %w", path, err)
+	}
+	return entries, nil
+}
+
+// Add records entry as the most recent message, keeping at most size
+// entries. An entry that was already recorded moves to the front.
+func Add(entry Entry, size int) error {
+	if size <= 0 || entry.Header == "" {
+		return nil
+	}
+
+	entries, err := Load()
+	if err != nil {
+		// Start over rather than failing every commit on a corrupt file
+		entries = nil
+	}
+
+	updated := []Entry{entry}
+	for _, e := range entries {
+		if e != entry {
+			updated = append(updated, e)
+		}
+	}
+	if len(updated) > size {
+		updated = updated[:size]
+	}
+
+	return save(updated)
+}
+
+func save(entries []Entry) error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to save history: %w", err)
+	}
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to save history: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to save history: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to save history: %w", err)
+	}
+	return nil
+}
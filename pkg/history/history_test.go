@@ -0,0 +1,85 @@
+package history
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"reflect"
+	"testing"
+
+	"github.com/denysvitali/git-cc/pkg/conventional"
+)
+
+func TestNewEntry(t *testing.T) {
+	entry := NewEntry("feat(ui): add history\n\nRecall past messages.\n\nRefs: #3\n")
+	expected := Entry{Header: "feat(ui): add history", Body: "Recall past messages.\n\nRefs: #3"}
+	if entry != expected {
+		t.Errorf("expected %+v, got %+v", expected, entry)
+	}
+	if entry.Message() != "feat(ui): add history\n\nRecall past messages.\n\nRefs: #3" {
+		t.Errorf("unexpected message %q", entry.Message())
+	}
+	if NewEntry("fix: x").Message() != "fix: x" {
+		t.Error("expected a header-only message to round-trip")
+	}
+}
+
+func TestEntryParse(t *testing.T) {
+	msg, err := NewEntry("fix(ui)!: crash\r\n\r\nBREAKING CHANGE: drops -x\r\n").Parse()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if msg.Type != "fix" || msg.Scope != "ui" || msg.Subject != "crash" || msg.BreakingChange() != "drops -x" {
+		t.Errorf("unexpected message %+v", msg)
+	}
+
+	if _, err := NewEntry("Merge branch 'other'").Parse(); !errors.Is(err, conventional.ErrNotConventional) {
+		t.Errorf("expected ErrNotConventional, got %v", err)
+	}
+}
+
+func TestAdd(t *testing.T) {
+	tempDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+
+	os.Chdir(tempDir)
+
+	if err := exec.Command("git", "init", "-q").Run(); err != nil {
+		t.Fatalf("failed to init git repo: %v", err)
+	}
+
+	if entries, err := Load(); err != nil || entries != nil {
+		t.Fatalf("expected no history, got %v, %v", entries, err)
+	}
+
+	for _, header := range []string{"chore: one", "chore: two", "chore: three", "chore: one"} {
+		if err := Add(Entry{Header: header}, 3); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	entries, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []Entry{{Header: "chore: one"}, {Header: "chore: three"}, {Header: "chore: two"}}
+	if !reflect.DeepEqual(entries, expected) {
+		t.Errorf("expected %v, got %v", expected, entries)
+	}
+
+	if err := Add(Entry{Header: "chore: four"}, 2); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if entries, _ := Load(); len(entries) != 2 || entries[0].Header != "chore: four" {
+		t.Errorf("expected the history to be trimmed, got %v", entries)
+	}
+
+	// A size of zero disables the history
+	if err := Add(Entry{Header: "chore: five"}, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if entries, _ := Load(); entries[0].Header == "chore: five" {
+		t.Error("expected nothing to be recorded")
+	}
+}
@@ -0,0 +1,87 @@
+// Package message checks conventional commit messages against the rules
+// git-cc applies, whether the message comes from the interface or from flags.
+package message
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/denysvitali/git-cc/pkg/conventional"
+)
+
+// Type is a commit type offered by git-cc.
+type Type struct {
+	Name        string
+	Description string
+}
+
+// Types are the commit types git-cc accepts, in the order they are offered.
+var Types = []Type{
+	{Name: "feat", Description: "A new feature"},
+	{Name: "fix", Description: "A bug fix"},
+	{Name: "docs", Description: "Documentation only changes"},
+	{Name: "style", Description: "Changes that do not affect the meaning of the code"},
+	{Name: "refactor", Description: "A code change that neither fixes a bug nor adds a feature"},
+	{Name: "perf", Description: "A code change that improves performance"},
+	{Name: "test", Description: "Adding missing tests or correcting existing tests"},
+	{Name: "build", Description: "Changes that affect the build system or external dependencies"},
+	{Name: "ci", Description: "Changes to CI configuration files and scripts"},
+	{Name: "chore", Description: "Other changes that don't modify src or test files"},
+}
+
+const (
+	// MaxScopeLength is the longest scope accepted.
+	MaxScopeLength = 50
+	// MaxSubjectLength is the longest subject accepted.
+	MaxSubjectLength = 100
+)
+
+// LintError lists the problems that make a message invalid.
+type LintError struct {
+	Problems []string
+}
+
+func (e *LintError) Error() string {
+	return "invalid commit message: " + strings.Join(e.Problems, "; ")
+}
+
+// Lint checks the message against the rules of git-cc and returns a
+// *LintError listing every problem found.
+func Lint(f conventional.Message) error {
+	var problems []string
+
+	switch {
+	case f.Type == "":
+		problems = append(problems, "the type is missing")
+	case !slices.ContainsFunc(Types, func(t Type) bool { return t.Name == f.Type }):
+		problems = append(problems, fmt.Sprintf("unknown type %q", f.Type))
+	}
+
+	switch {
+	case strings.ContainsAny(f.Scope, "()\n"):
+		problems = append(problems, "the scope must not contain parentheses or line breaks")
+	case len(f.Scope) > MaxScopeLength:
+		problems = append(problems, fmt.Sprintf("the scope is longer than %d characters", MaxScopeLength))
+	}
+
+	switch {
+	case strings.TrimSpace(f.Subject) == "":
+		problems = append(problems, "the subject is missing")
+	case strings.Contains(f.Subject, "\n"):
+		problems = append(problems, "the subject must fit on one line")
+	case len(f.Subject) > MaxSubjectLength:
+		problems = append(problems, fmt.Sprintf("the subject is longer than %d characters", MaxSubjectLength))
+	}
+
+	for _, footer := range f.Footers {
+		if !footer.Valid() {
+			problems = append(problems, fmt.Sprintf("invalid footer %q, expected \"Token: value\"", footer.String()))
+		}
+	}
+
+	if len(problems) > 0 {
+		return &LintError{Problems: problems}
+	}
+	return nil
+}
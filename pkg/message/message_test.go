@@ -0,0 +1,37 @@
+package message
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/denysvitali/git-cc/pkg/conventional"
+)
+
+func TestLint(t *testing.T) {
+	valid := conventional.Message{
+		Type: "feat", Scope: "ui", Subject: "add",
+		Footers: []conventional.Footer{{Token: "Refs", Separator: conventional.SeparatorHash, Value: "12"}},
+	}
+	if err := Lint(valid); err != nil {
+		t.Errorf("expected a valid message, got %v", err)
+	}
+
+	err := Lint(conventional.Message{
+		Type: "feature", Scope: "a(b)",
+		Footers: []conventional.Footer{{Token: "no pe", Separator: conventional.SeparatorColon, Value: "x"}},
+	})
+	var lintErr *LintError
+	if !errors.As(err, &lintErr) {
+		t.Fatalf("expected a LintError, got %v", err)
+	}
+	expected := []string{
+		`unknown type "feature"`,
+		"the scope must not contain parentheses or line breaks",
+		"the subject is missing",
+		`invalid footer "no pe: x", expected "Token: value"`,
+	}
+	if !reflect.DeepEqual(lintErr.Problems, expected) {
+		t.Errorf("expected problems %q, got %q", expected, lintErr.Problems)
+	}
+}
@@ -0,0 +1,63 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// bodyHeight is the number of lines of the body editor.
+const bodyHeight = 8
+
+func newBodyInput() textarea.Model {
+	body := textarea.New()
+	body.Placeholder = "Explain what and why. End with footers such as \"Refs: #12\"."
+	body.ShowLineNumbers = false
+	body.CharLimit = 0
+	body.SetWidth(72)
+	body.SetHeight(bodyHeight)
+	return body
+}
+
+// enterBody switches from the message step to the body editor.
+func (m Model) enterBody() (tea.Model, tea.Cmd) {
+	m.step = StepBody
+	m.message.Blur()
+	m.body.Focus()
+	return m, textarea.Blink
+}
+
+func (m Model) updateBody(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "shift+tab":
+		m.step = StepMessage
+		m.body.Blur()
+		m.message.Focus()
+		return m, nil
+
+	case "ctrl+d":
+		return m.submitMessage()
+	}
+
+	var cmd tea.Cmd
+	m.body, cmd = m.body.Update(msg)
+	return m, cmd
+}
+
+// bodyText returns the body and footers entered by the user.
+func (m Model) bodyText() string {
+	return strings.TrimSpace(m.body.Value())
+}
+
+func (m Model) bodyView() string {
+	s := m.splitHeader() + m.submoduleHeader()
+	s += titleStyle.Render("Enter commit body (optional):") + "\n"
+	s += promptStyle.Render(m.buildHeader()) + "\n\n"
+	s += m.body.View() + "\n\n"
+	if m.submitErr != nil {
+		s += errorStyle.Render(m.submitErr.Error()) + "\n\n"
+	}
+	s += promptStyle.Render("ctrl+d: commit • esc: back to subject")
+	return s
+}
@@ -0,0 +1,44 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestBodyStep(t *testing.T) {
+	model := NewModel(Options{DryRun: true})
+	model.step = StepMessage
+	model.list.SetItems([]list.Item{item{commitType: "feat", description: "A new feature"}})
+	model.list.Select(0)
+	model.message.Focus()
+	model.message.SetValue("add body")
+
+	newModel, _ := model.Update(tea.KeyMsg{Type: tea.KeyTab})
+	model = newModel.(Model)
+	if model.step != StepBody || !model.body.Focused() {
+		t.Fatalf("Expected focused StepBody (%d), got %d", StepBody, model.step)
+	}
+
+	// Enter adds a line instead of committing
+	model.body.SetValue("First line.")
+	newModel, _ = model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model = newModel.(Model)
+	if model.step != StepBody || model.commitMsg != "" {
+		t.Fatal("Expected enter to stay in the body editor")
+	}
+
+	newModel, _ = model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if newModel.(Model).step != StepMessage {
+		t.Errorf("Expected esc to go back to StepMessage (%d)", StepMessage)
+	}
+
+	newModel, cmd := model.Update(tea.KeyMsg{Type: tea.KeyCtrlD})
+	if cmd == nil {
+		t.Error("Expected quit command in dry-run mode")
+	}
+	if msg := newModel.(Model).CommitMessage(); msg != "feat: add body\n\nFirst line." {
+		t.Errorf("Expected body in message, got %q", msg)
+	}
+}
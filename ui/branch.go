@@ -0,0 +1,123 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/denysvitali/git-cc/pkg/config"
+	"github.com/denysvitali/git-cc/pkg/git"
+)
+
+// maxBranchSlugLength limits the part of a branch name taken from the scope
+// or subject.
+const maxBranchSlugLength = 40
+
+// checkBranch stops on the protected branch step when HEAD is on a
+// protected branch.
+func (m Model) checkBranch() Model {
+	if len(m.opts.ProtectedBranches) == 0 || m.opts.DryRun || m.operation != "" {
+		return m
+	}
+	branch, err := git.CurrentBranch()
+	if err != nil || !config.IsProtectedBranch(m.opts.ProtectedBranches, branch) {
+		return m
+	}
+	m.protectedBranch = branch
+	m.step = StepProtectedBranch
+	return m
+}
+
+func (m Model) updateProtectedBranch(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "b":
+		m.newBranch = true
+		return m.continueStart(), nil
+
+	case "c":
+		if !m.opts.BlockProtectedBranches {
+			return m.continueStart(), nil
+		}
+
+	case "q", "esc":
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+// branchName returns a name for a new branch from the commit type and the
+// scope, or the subject if there is no scope, such as "feat/parser". It
+// adds a number if a branch with that name already exists.
+func branchName(commitType, scope, subject string) string {
+	slug := slugify(scope)
+	if slug == "" {
+		slug = slugify(subject)
+	}
+	name := commitType
+	if slug != "" {
+		name += "/" + slug
+	}
+
+	candidate := name
+	for n := 2; git.BranchExists(candidate); n++ {
+		candidate = fmt.Sprintf("%s-%d", name, n)
+	}
+	return candidate
+}
+
+// slugify lowercases s and replaces everything but letters and digits with
+// dashes, for use in a branch name.
+func slugify(s string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(s) {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			dash = false
+			b.WriteRune(r)
+		} else {
+			dash = true
+		}
+		if b.Len() >= maxBranchSlugLength {
+			break
+		}
+	}
+	return b.String()
+}
+
+// createBranch switches to a new branch named after the commit if the user
+// chose not to commit to the protected branch.
+func (m Model) createBranch() (Model, error) {
+	if !m.newBranch || m.submodules.active() {
+		return m, nil
+	}
+	selected := m.list.SelectedItem().(item)
+	name := branchName(selected.commitType, m.scope.Value(), m.message.Value())
+	if err := git.CreateBranch(name); err != nil {
+		return m, err
+	}
+	m.newBranch = false
+	m.createdBranch = name
+	return m, nil
+}
+
+func (m Model) protectedBranchView() string {
+	s := titleStyle.Render(fmt.Sprintf("%s is a protected branch", m.protectedBranch)) + "\n\n"
+	if m.opts.BlockProtectedBranches {
+		s += "Commits to it are blocked by cc.protectedBranchAction.\n"
+	} else {
+		s += "Changes to it usually go through a separate branch.\n"
+	}
+	s += "git-cc can create a branch named after the type and scope of the commit\n"
+	s += "and commit there instead.\n\n"
+
+	help := "b: commit on a new branch"
+	if !m.opts.BlockProtectedBranches {
+		help += fmt.Sprintf(" • c: commit to %s anyway", m.protectedBranch)
+	}
+	help += " • q: quit"
+	return s + promptStyle.Render(help)
+}
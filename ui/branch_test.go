@@ -0,0 +1,91 @@
+package ui
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/denysvitali/git-cc/pkg/git"
+)
+
+func TestSlugify(t *testing.T) {
+	tests := map[string]string{
+		"":                    "",
+		"parser":              "parser",
+		"UI, config":          "ui-config",
+		"  handle a/b paths!": "handle-a-b-paths",
+	}
+	for input, expected := range tests {
+		if slug := slugify(input); slug != expected {
+			t.Errorf("slugify(%q) = %q, expected %q", input, slug, expected)
+		}
+	}
+}
+
+func TestProtectedBranchNewBranch(t *testing.T) {
+	setupRepo(t, repoOptions{branch: "main", commit: true})
+	runGit(t, "branch", "feat/io")
+
+	model := NewModel(Options{ProtectedBranches: []string{"main"}, BlockProtectedBranches: true})
+	if model.step != StepProtectedBranch {
+		t.Fatalf("Expected StepProtectedBranch (%d), got %d", StepProtectedBranch, model.step)
+	}
+
+	// Blocked, so committing to main is not offered
+	newModel, _ := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'c'}})
+	model = newModel.(Model)
+	if model.step != StepProtectedBranch {
+		t.Fatalf("Expected to stay on StepProtectedBranch, got %d", model.step)
+	}
+	if view := model.View(); strings.Contains(view, "anyway") {
+		t.Errorf("Expected no offer to commit to main, got %q", view)
+	}
+
+	newModel, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'b'}})
+	model = newModel.(Model)
+	if model.step != StepTypeSelect {
+		t.Fatalf("Expected StepTypeSelect (%d), got %d", StepTypeSelect, model.step)
+	}
+
+	newModel, _ = model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model = newModel.(Model)
+	model.scope.SetValue("io")
+	newModel, _ = model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model = newModel.(Model)
+	model.message.SetValue("add a")
+	newModel, _ = model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model = finishCommit(t, newModel.(Model))
+
+	if model.CreatedBranch() != "feat/io-2" {
+		t.Errorf("Expected branch feat/io-2, got %q", model.CreatedBranch())
+	}
+	if branch, _ := git.CurrentBranch(); branch != "feat/io-2" {
+		t.Errorf("Expected HEAD on feat/io-2, got %q", branch)
+	}
+	log, _ := exec.Command("git", "log", "-1", "--format=%s", "main").Output()
+	if string(log) != "initial\n" {
+		t.Errorf("Expected main to be unchanged, got %q", string(log))
+	}
+}
+
+func TestProtectedBranchWarning(t *testing.T) {
+	setupRepo(t, repoOptions{branch: "main", commit: true})
+
+	model := NewModel(Options{ProtectedBranches: []string{"release/*", "ma*"}})
+	if model.step != StepProtectedBranch {
+		t.Fatalf("Expected StepProtectedBranch (%d), got %d", StepProtectedBranch, model.step)
+	}
+
+	newModel, _ := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'c'}})
+	model = newModel.(Model)
+	if model.step != StepTypeSelect || model.newBranch {
+		t.Errorf("Expected to continue on main, got step %d", model.step)
+	}
+
+	model = NewModel(Options{ProtectedBranches: []string{"release/*"}})
+	if model.step != StepTypeSelect {
+		t.Errorf("Expected main not to be protected, got step %d", model.step)
+	}
+}
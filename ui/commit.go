@@ -0,0 +1,172 @@
+package ui
+
+import (
+	"context"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/denysvitali/git-cc/pkg/git"
+)
+
+// commitChromeHeight is the number of lines of the committing step that are
+// not hook output (title, help and padding).
+const commitChromeHeight = 6
+
+// commitOutputMsg carries a chunk of output from git and its hooks.
+type commitOutputMsg struct {
+	chunk string
+	// done is set once the output channel is closed.
+	done bool
+}
+
+// commitDoneMsg carries the result of a commit run in the background.
+type commitDoneMsg struct {
+	result *git.CommitResult
+}
+
+// outputWriter forwards everything written to it to a channel.
+type outputWriter chan<- string
+
+func (w outputWriter) Write(p []byte) (int, error) {
+	w <- string(p)
+	return len(p), nil
+}
+
+// runCommit commits in the background, streaming the output to out and
+// closing it once git exits.
+func runCommit(ctx context.Context, message string, opts git.CommitOptions, out chan string) tea.Cmd {
+	return func() tea.Msg {
+		opts.Output = outputWriter(out)
+		result := git.CommitWithResult(ctx, message, opts)
+		close(out)
+		return commitDoneMsg{result: result}
+	}
+}
+
+// waitForOutput waits for the next chunk of commit output.
+func waitForOutput(out chan string) tea.Cmd {
+	return func() tea.Msg {
+		chunk, ok := <-out
+		return commitOutputMsg{chunk: chunk, done: !ok}
+	}
+}
+
+// commitDir returns the submodule the next commit is made in, or "" for the
+// repository itself.
+func (m Model) commitDir() string {
+	if m.submodules.active() {
+		return m.submodules.path()
+	}
+	return ""
+}
+
+// startCommit switches to the committing step and runs the commit, passing
+// extraArgs to git commit in addition to the configured arguments.
+func (m Model) startCommit(extraArgs ...string) (tea.Model, tea.Cmd) {
+	m = m.snapshotStagedFiles()
+	ctx, cancel := context.WithCancel(context.Background())
+	out := make(chan string, 64)
+
+	m.step = StepCommitting
+	m.cancelCommit = cancel
+	m.cancelling = false
+	m.output = nil
+	m.outputChan = out
+	m.outputView.SetContent("")
+
+	m.commitOpts = git.CommitOptions{
+		Args:              append(append([]string(nil), m.opts.CommitArgs...), extraArgs...),
+		Timeout:           m.opts.Timeout,
+		BlockDetachedHead: m.opts.BlockDetachedHead,
+		AllowDetachedHead: m.allowDetached,
+		Dir:               m.commitDir(),
+	}
+	return m, tea.Batch(m.spinner.Tick, runCommit(ctx, m.commitMsg, m.commitOpts, out), waitForOutput(out))
+}
+
+func (m Model) updateCommitting(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case commitOutputMsg:
+		if msg.done {
+			return m, nil
+		}
+		chunk := strings.ReplaceAll(msg.chunk, "\r\n", "\n")
+		m.output = append(m.output, strings.ReplaceAll(chunk, "\r", "\n"))
+		m.outputView.SetContent(strings.Join(m.output, ""))
+		m.outputView.GotoBottom()
+		return m, waitForOutput(m.outputChan)
+
+	case commitDoneMsg:
+		m.cancelCommit()
+		m.gitResult = msg.result
+		if msg.result.Success {
+			m.clearDraft()
+			m = m.recordHistory()
+		} else {
+			m.saveDraft()
+		}
+		if m.cancelling {
+			m.restoreSplit()
+			return m, tea.Quit
+		}
+		return m.finishCommit()
+
+	case spinner.TickMsg:
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+
+	case tea.KeyMsg:
+		if msg.String() == "ctrl+c" {
+			if m.cancelling {
+				// Second Ctrl+C: stop waiting for git to clean up
+				return m, tea.Quit
+			}
+			m.cancelling = true
+			m.cancelCommit()
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.outputView, cmd = m.outputView.Update(msg)
+		return m, cmd
+	}
+
+	return m, nil
+}
+
+// finishCommit moves on after the commit completed.
+func (m Model) finishCommit() (tea.Model, tea.Cmd) {
+	if m.split != nil {
+		return m.finishSplitCommit()
+	}
+	if m.submodules.active() {
+		return m.finishSubmoduleCommit()
+	}
+
+	if !m.gitResult.Success {
+		m.step = StepError
+		m.showError = true
+		return m, nil
+	}
+
+	if m.opts.Summary {
+		return m.showSummary()
+	}
+	return m, tea.Quit
+}
+
+func (m Model) committingView() string {
+	title := m.spinner.View() + " Committing..."
+	if m.cancelling {
+		title = m.spinner.View() + " Cancelling..."
+	}
+
+	s := titleStyle.Render(title) + "\n\n"
+	if len(m.output) > 0 {
+		s += m.outputView.View() + "\n"
+	}
+	s += "\n" + promptStyle.Render("Ctrl+C to cancel")
+	return s
+}
@@ -0,0 +1,189 @@
+package ui
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/denysvitali/git-cc/pkg/git"
+)
+
+// finishCommit runs the commit started by the model synchronously and feeds
+// the result back, as the Bubble Tea runtime would.
+func finishCommit(t *testing.T, model Model) Model {
+	t.Helper()
+
+	if model.step != StepCommitting {
+		t.Fatalf("Expected StepCommitting (%d), got %d", StepCommitting, model.step)
+	}
+	result := git.CommitWithResult(context.Background(), model.commitMsg, model.commitOpts)
+	newModel, _ := model.Update(commitDoneMsg{result: result})
+	return newModel.(Model)
+}
+
+// repoOptions describes the repository created by setupRepo.
+type repoOptions struct {
+	// branch is the initial branch, or the default of git if empty.
+	branch string
+	// commit creates an empty initial commit before the files are staged.
+	commit bool
+	// hook is installed as the pre-commit hook if set.
+	hook string
+	// files maps the files created and staged to their content. Only a.txt
+	// is staged if empty.
+	files map[string]string
+}
+
+// setupRepo creates a repository with a test identity in a temporary
+// directory, which is the working directory for the rest of the test.
+func setupRepo(t *testing.T, opts repoOptions) {
+	t.Helper()
+
+	originalDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get the working directory: %v", err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatalf("Failed to change directory: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := os.Chdir(originalDir); err != nil {
+			t.Errorf("Failed to restore the working directory: %v", err)
+		}
+	})
+
+	if opts.branch != "" {
+		runGit(t, "init", "-q", "-b", opts.branch)
+	} else {
+		runGit(t, "init", "-q")
+	}
+	runGit(t, "config", "user.name", "Test User")
+	runGit(t, "config", "user.email", "test@example.com")
+	if opts.commit {
+		runGit(t, "commit", "-q", "--allow-empty", "-m", "initial")
+	}
+	if opts.hook != "" {
+		if err := writeHook(opts.hook); err != nil {
+			t.Fatalf("Failed to create hook: %v", err)
+		}
+	}
+
+	files := opts.files
+	if len(files) == 0 {
+		files = map[string]string{"a.txt": "a"}
+	}
+	for name, content := range files {
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+	}
+	runGit(t, "add", "-A")
+}
+
+// runGit runs git in the working directory and fails the test if it fails.
+func runGit(t *testing.T, args ...string) {
+	t.Helper()
+
+	if output, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+		t.Fatalf("Failed to run git %v: %v\n%s", args, err, output)
+	}
+}
+
+// writeHook installs hook as the pre-commit hook of the current repository.
+func writeHook(hook string) error {
+	return os.WriteFile(filepath.Join(".git", "hooks", "pre-commit"), []byte(hook), 0755)
+}
+
+func TestRunCommitStreamsOutput(t *testing.T) {
+	setupRepo(t, repoOptions{hook: "#!/bin/sh\necho checking files\n"})
+
+	out := make(chan string, 64)
+	done := make(chan tea.Msg)
+	go func() { done <- runCommit(context.Background(), "feat: stream", git.CommitOptions{}, out)() }()
+
+	var output strings.Builder
+	for {
+		msg := waitForOutput(out)().(commitOutputMsg)
+		if msg.done {
+			break
+		}
+		output.WriteString(msg.chunk)
+	}
+
+	result := (<-done).(commitDoneMsg).result
+	if !result.Success {
+		t.Errorf("Expected commit to succeed, got %s: %s", result.Message, result.Details)
+	}
+	if !strings.Contains(output.String(), "checking files") {
+		t.Errorf("Expected hook output to be streamed, got %q", output.String())
+	}
+}
+
+func TestRunCommitCancel(t *testing.T) {
+	setupRepo(t, repoOptions{hook: "#!/bin/sh\nsleep 30\n"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	out := make(chan string, 64)
+	done := make(chan tea.Msg)
+	go func() { done <- runCommit(ctx, "feat: cancel", git.CommitOptions{}, out)() }()
+	go func() {
+		for range out {
+		}
+	}()
+
+	cancel()
+	result := (<-done).(commitDoneMsg).result
+	if result.Success {
+		t.Error("Expected cancelled commit to fail")
+	}
+}
+
+func TestCommittingStep(t *testing.T) {
+	model := InitialModel()
+	newModel, _ := model.Update(tea.WindowSizeMsg{Width: 80, Height: 24})
+	model = newModel.(Model)
+	model.step = StepMessage
+	model.message.SetValue("add streaming")
+
+	// The returned commands are not run, so nothing is committed here
+	newModel, cmd := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model = newModel.(Model)
+	if model.step != StepCommitting {
+		t.Fatalf("Expected StepCommitting (%d), got %d", StepCommitting, model.step)
+	}
+	if cmd == nil {
+		t.Fatal("Expected commands running the commit")
+	}
+
+	newModel, _ = model.Update(commitOutputMsg{chunk: "running hook\r\n"})
+	model = newModel.(Model)
+	if view := model.View(); !strings.Contains(view, "running hook") {
+		t.Errorf("Expected hook output in view, got %q", view)
+	}
+
+	// Ctrl+C cancels, then the result makes the program quit
+	newModel, _ = model.Update(tea.KeyMsg{Type: tea.KeyCtrlC})
+	model = newModel.(Model)
+	if !model.cancelling {
+		t.Error("Expected commit to be cancelling")
+	}
+	_, cmd = model.Update(commitDoneMsg{result: &git.CommitResult{Message: "Commit cancelled"}})
+	if cmd == nil {
+		t.Error("Expected quit command after cancellation")
+	}
+
+	// A failed commit shows the error step
+	model.cancelling = false
+	newModel, _ = model.Update(commitDoneMsg{result: &git.CommitResult{Message: "Pre-commit hook failed"}})
+	if newModel.(Model).step != StepError {
+		t.Errorf("Expected StepError (%d), got %d", StepError, newModel.(Model).step)
+	}
+}
@@ -0,0 +1,113 @@
+package ui
+
+import (
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/denysvitali/git-cc/pkg/conventional"
+	"github.com/denysvitali/git-cc/pkg/draft"
+)
+
+// currentDraft returns the message in progress.
+func (m Model) currentDraft() *draft.Draft {
+	d := &draft.Draft{
+		Scope:   m.scope.Value(),
+		Subject: m.message.Value(),
+	}
+	if selected, ok := m.list.SelectedItem().(item); ok {
+		d.Type = selected.commitType
+	}
+	body, footers := conventional.SplitBody(m.bodyText())
+	d.Body = body
+	for _, footer := range footers {
+		d.Footers = append(d.Footers, footer.String())
+	}
+	return d
+}
+
+// saveDraft stores the message in progress. Errors are ignored: losing the
+// draft must never get in the way of committing.
+func (m Model) saveDraft() {
+	if !m.opts.Draft {
+		return
+	}
+	_ = draft.Save(m.currentDraft())
+}
+
+// clearDraft removes the saved draft once it has been committed.
+func (m Model) clearDraft() {
+	if !m.opts.Draft {
+		return
+	}
+	_ = draft.Clear()
+}
+
+// offerDraft switches to the restore prompt if a draft was saved by an
+// earlier run.
+func (m Model) offerDraft() Model {
+	if !m.opts.Draft {
+		return m
+	}
+	d, err := draft.Load()
+	if err != nil || d == nil || d.IsEmpty() {
+		return m
+	}
+	m.draft = d
+	m.step = StepRestoreDraft
+	return m
+}
+
+func (m Model) updateRestoreDraft(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "y", "enter":
+		return m.restoreDraft()
+
+	case "n", "esc":
+		m.draft = nil
+		m.clearDraft()
+		return m.continueStart(), nil
+	}
+	return m, nil
+}
+
+// restoreDraft fills in the saved draft and continues at the message step,
+// or at the staging step if nothing is staged.
+func (m Model) restoreDraft() (tea.Model, tea.Cmd) {
+	m = m.applyDraft(m.draft)
+	m.draft = nil
+
+	if m.opts.Stage {
+		return m.enterStaging(), nil
+	}
+	m.step = StepMessage
+	m.message.Focus()
+	return m, textinput.Blink
+}
+
+// applyDraft fills the type, scope, subject and body from d. An unknown type
+// leaves the selected type unchanged.
+func (m Model) applyDraft(d *draft.Draft) Model {
+	for i, listItem := range m.list.Items() {
+		if listItem.(item).commitType == d.Type {
+			m.list.Select(i)
+			break
+		}
+	}
+	m.scope.SetValue(d.Scope)
+	m.message.SetValue(d.Subject)
+	m.body.SetValue(d.Text())
+	return m
+}
+
+// draftMessage returns the saved draft as it would be committed.
+func (m Model) draftMessage() string {
+	d := m.draft
+	return conventional.Message{Type: d.Type, Scope: d.Scope, Subject: d.Subject, Body: d.Text()}.String()
+}
+
+func (m Model) restoreDraftView() string {
+	s := titleStyle.Render("Restore the draft from the last run?") + "\n\n"
+	s += m.draftMessage() + "\n"
+	s += "\n" + promptStyle.Render("y: restore • n: discard")
+	return s
+}
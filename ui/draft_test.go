@@ -0,0 +1,84 @@
+package ui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/denysvitali/git-cc/pkg/draft"
+)
+
+func TestDraftSavedAndRestored(t *testing.T) {
+	setupRepo(t, repoOptions{hook: "#!/bin/sh\nexit 1\n"})
+
+	model := NewModel(Options{Draft: true})
+	model.list.Select(1) // fix
+	newModel, _ := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model = newModel.(Model)
+	model.scope.SetValue("parser")
+	newModel, _ = model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model = newModel.(Model)
+	model.message.SetValue("handle empty input")
+	newModel, _ = model.Update(tea.KeyMsg{Type: tea.KeyTab})
+	model = newModel.(Model)
+	model.body.SetValue("It crashed.\n\nRefs: #7")
+	newModel, _ = model.Update(tea.KeyMsg{Type: tea.KeyCtrlD})
+	model = finishCommit(t, newModel.(Model))
+	if model.step != StepError {
+		t.Fatalf("Expected the hook to fail the commit, got %+v", model.gitResult)
+	}
+
+	saved, err := draft.Load()
+	if err != nil || saved == nil {
+		t.Fatalf("Expected a saved draft, got %v", err)
+	}
+	expected := draft.Draft{Type: "fix", Scope: "parser", Subject: "handle empty input", Body: "It crashed.", Footers: []string{"Refs: #7"}}
+	if saved.Type != expected.Type || saved.Scope != expected.Scope || saved.Subject != expected.Subject ||
+		saved.Body != expected.Body || len(saved.Footers) != 1 || saved.Footers[0] != "Refs: #7" {
+		t.Errorf("Expected %+v, got %+v", expected, saved)
+	}
+
+	// The next run offers to restore it
+	model = NewModel(Options{Draft: true})
+	if model.step != StepRestoreDraft {
+		t.Fatalf("Expected StepRestoreDraft (%d), got %d", StepRestoreDraft, model.step)
+	}
+	newModel, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}})
+	model = newModel.(Model)
+	if model.step != StepMessage {
+		t.Fatalf("Expected StepMessage (%d), got %d", StepMessage, model.step)
+	}
+	if msg := model.buildCommitMessage(); msg != "fix(parser): handle empty input\n\nIt crashed.\n\nRefs: #7" {
+		t.Errorf("Unexpected restored message %q", msg)
+	}
+
+	// A successful commit clears it
+	if err := writeHook("#!/bin/sh\n"); err != nil {
+		t.Fatalf("Failed to replace hook: %v", err)
+	}
+	newModel, _ = model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model = finishCommit(t, newModel.(Model))
+	if !model.gitResult.Success {
+		t.Fatalf("Expected commit to succeed, got %+v", model.gitResult)
+	}
+	if d, _ := draft.Load(); d != nil {
+		t.Errorf("Expected the draft to be cleared, got %+v", d)
+	}
+}
+
+func TestDraftDiscarded(t *testing.T) {
+	setupRepo(t, repoOptions{})
+	if err := draft.Save(&draft.Draft{Type: "feat", Subject: "old"}); err != nil {
+		t.Fatalf("Failed to save draft: %v", err)
+	}
+
+	model := NewModel(Options{Draft: true})
+	newModel, _ := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}})
+	model = newModel.(Model)
+	if model.step != StepTypeSelect {
+		t.Errorf("Expected StepTypeSelect (%d), got %d", StepTypeSelect, model.step)
+	}
+	if d, _ := draft.Load(); d != nil {
+		t.Errorf("Expected the draft to be discarded, got %+v", d)
+	}
+}
@@ -0,0 +1,134 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/denysvitali/git-cc/pkg/draft"
+	"github.com/denysvitali/git-cc/pkg/history"
+)
+
+// historyItem is a past message in the history search.
+type historyItem struct {
+	entry history.Entry
+}
+
+func (i historyItem) Title() string { return i.entry.Header }
+func (i historyItem) Description() string {
+	line, _, _ := strings.Cut(i.entry.Body, "\n")
+	return line
+}
+func (i historyItem) FilterValue() string { return i.entry.Header + " " + i.entry.Body }
+
+// loadHistory reads the messages committed earlier in this repository.
+func (m Model) loadHistory() Model {
+	m.historyIndex = -1
+	if m.opts.HistorySize <= 0 {
+		return m
+	}
+	m.history, _ = history.Load()
+	return m
+}
+
+// recordHistory adds the committed message to the history.
+func (m Model) recordHistory() Model {
+	if m.opts.HistorySize <= 0 {
+		return m
+	}
+	_ = history.Add(history.NewEntry(m.commitMsg), m.opts.HistorySize)
+	return m.loadHistory()
+}
+
+// recallHistory replaces the message with an older (delta 1) or newer
+// (delta -1) one from the history. Going past the newest entry brings back
+// what the user had typed.
+func (m Model) recallHistory(delta int) Model {
+	index := m.historyIndex + delta
+	if index < -1 || index >= len(m.history) {
+		return m
+	}
+
+	if m.historyIndex == -1 {
+		m.historyStash = m.currentDraft()
+	}
+	m.historyIndex = index
+
+	if index == -1 {
+		m = m.applyDraft(m.historyStash)
+		m.historyStash = nil
+	} else {
+		m = m.applyEntry(m.history[index])
+	}
+	m.message.CursorEnd()
+	return m
+}
+
+// applyEntry fills the message from a history entry.
+func (m Model) applyEntry(entry history.Entry) Model {
+	d := &draft.Draft{Subject: entry.Header, Body: entry.Body}
+	if selected, ok := m.list.SelectedItem().(item); ok {
+		d.Type = selected.commitType
+	}
+	if msg, err := entry.Parse(); err == nil {
+		d.Type, d.Scope, d.Subject = msg.Type, msg.Scope, msg.Subject
+	}
+	return m.applyDraft(d)
+}
+
+// enterHistory opens the fuzzy search over past messages.
+func (m Model) enterHistory() (tea.Model, tea.Cmd) {
+	items := make([]list.Item, len(m.history))
+	for i, entry := range m.history {
+		items[i] = historyItem{entry: entry}
+	}
+
+	width, height := m.width, m.height
+	if width == 0 || height == 0 {
+		width, height = 80, 20
+	}
+	m.historyList = list.New(items, list.NewDefaultDelegate(), width, height)
+	m.historyList.Title = "Message history"
+	m.historyList.SetShowHelp(true)
+	// q would quit and drop the message being written. Setting the key map
+	// alone does not last, as the list enables it again when filtering ends.
+	m.historyList.DisableQuitKeybindings()
+
+	// Start typing the search right away
+	m.historyList, _ = m.historyList.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'/'}})
+
+	m.step = StepHistory
+	m.message.Blur()
+	return m, nil
+}
+
+func (m Model) updateHistory(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		if m.historyList.FilterState() == list.Filtering {
+			m.historyList, _ = m.historyList.Update(msg)
+		}
+		if selected, ok := m.historyList.SelectedItem().(historyItem); ok {
+			m = m.applyEntry(selected.entry)
+			m.message.CursorEnd()
+		}
+		return m.leaveHistory()
+
+	case "esc":
+		if m.historyList.FilterState() == list.Unfiltered {
+			return m.leaveHistory()
+		}
+	}
+
+	var cmd tea.Cmd
+	m.historyList, cmd = m.historyList.Update(msg)
+	return m, cmd
+}
+
+func (m Model) leaveHistory() (tea.Model, tea.Cmd) {
+	m.step = StepMessage
+	m.message.Focus()
+	return m, textinput.Blink
+}
@@ -0,0 +1,139 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/denysvitali/git-cc/pkg/history"
+)
+
+func TestHistoryRecall(t *testing.T) {
+	setupRepo(t, repoOptions{})
+
+	model := NewModel(Options{HistorySize: 10})
+	model.list.Select(9) // chore
+	model.step = StepMessage
+	model.scope.SetValue("deps")
+	model.message.SetValue("bump lipgloss")
+	model.body.SetValue("Refs: #4")
+	newModel, _ := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model = finishCommit(t, newModel.(Model))
+	if !model.gitResult.Success {
+		t.Fatalf("Expected commit to succeed, got %+v", model.gitResult)
+	}
+	if err := history.Add(history.Entry{Header: "fix(ui): older fix"}, 10); err != nil {
+		t.Fatalf("Failed to add history: %v", err)
+	}
+	if err := history.Add(history.NewEntry("chore(deps): bump lipgloss\n\nRefs: #4"), 10); err != nil {
+		t.Fatalf("Failed to add history: %v", err)
+	}
+
+	model = NewModel(Options{HistorySize: 10})
+	model.step = StepMessage
+	model.message.Focus()
+	model.message.SetValue("typed")
+
+	newModel, _ = model.Update(tea.KeyMsg{Type: tea.KeyUp})
+	model = newModel.(Model)
+	if msg := model.buildCommitMessage(); msg != "chore(deps): bump lipgloss\n\nRefs: #4" {
+		t.Errorf("Expected the latest message, got %q", msg)
+	}
+
+	newModel, _ = model.Update(tea.KeyMsg{Type: tea.KeyUp})
+	model = newModel.(Model)
+	if msg := model.buildCommitMessage(); msg != "fix(ui): older fix" {
+		t.Errorf("Expected the older message, got %q", msg)
+	}
+
+	// Past the oldest entry nothing changes
+	newModel, _ = model.Update(tea.KeyMsg{Type: tea.KeyUp})
+	model = newModel.(Model)
+	if model.historyIndex != 1 {
+		t.Errorf("Expected to stay on the oldest entry, got %d", model.historyIndex)
+	}
+
+	newModel, _ = model.Update(tea.KeyMsg{Type: tea.KeyDown})
+	newModel, _ = newModel.Update(tea.KeyMsg{Type: tea.KeyDown})
+	model = newModel.(Model)
+	if msg := model.buildCommitMessage(); msg != "feat: typed" {
+		t.Errorf("Expected the typed message back, got %q", msg)
+	}
+}
+
+func TestHistorySearch(t *testing.T) {
+	setupRepo(t, repoOptions{})
+	for _, header := range []string{"docs: fix typo", "chore(deps): bump bubbles", "feat(ui): add search"} {
+		if err := history.Add(history.Entry{Header: header}, 10); err != nil {
+			t.Fatalf("Failed to add history: %v", err)
+		}
+	}
+
+	model := NewModel(Options{HistorySize: 10})
+	model.step = StepMessage
+	model.message.Focus()
+
+	newModel, _ := model.Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+	model = newModel.(Model)
+	if model.step != StepHistory || model.historyList.FilterState() != list.Filtering {
+		t.Fatalf("Expected the history search to be open, got step %d", model.step)
+	}
+
+	newModel, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("bubbles"), Paste: true})
+	model = feedFilterMatches(newModel.(Model), cmd)
+
+	newModel, _ = model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model = newModel.(Model)
+	if model.step != StepMessage {
+		t.Fatalf("Expected StepMessage (%d), got %d", StepMessage, model.step)
+	}
+	if msg := model.buildCommitMessage(); msg != "chore(deps): bump bubbles" {
+		t.Errorf("Expected the matching message, got %q", msg)
+	}
+}
+
+func TestHistoryQuitKey(t *testing.T) {
+	setupRepo(t, repoOptions{})
+	if err := history.Add(history.Entry{Header: "docs: fix typo"}, 10); err != nil {
+		t.Fatalf("Failed to add history: %v", err)
+	}
+
+	model := NewModel(Options{HistorySize: 10})
+	model.step = StepMessage
+	model.message.Focus()
+
+	newModel, _ := model.Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+	model = newModel.(Model)
+	// Cancel the search, then press q in the list
+	newModel, _ = model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	model = newModel.(Model)
+	newModel, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'q'}})
+	model = newModel.(Model)
+	if model.step != StepHistory {
+		t.Fatalf("Expected StepHistory (%d), got %d", StepHistory, model.step)
+	}
+	if cmd != nil {
+		if _, ok := cmd().(tea.QuitMsg); ok {
+			t.Error("Expected q not to quit from the history")
+		}
+	}
+}
+
+// feedFilterMatches runs cmd and feeds the filter results the list computes
+// in the background back into the model, as the Bubble Tea runtime would.
+func feedFilterMatches(model Model, cmd tea.Cmd) Model {
+	if cmd == nil {
+		return model
+	}
+	switch msg := cmd().(type) {
+	case tea.BatchMsg:
+		for _, c := range msg {
+			model = feedFilterMatches(model, c)
+		}
+	case list.FilterMatchesMsg:
+		newModel, _ := model.Update(msg)
+		model = newModel.(Model)
+	}
+	return model
+}
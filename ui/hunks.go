@@ -0,0 +1,183 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/denysvitali/git-cc/pkg/git"
+)
+
+// hunkChromeHeight is the number of lines of the hunk step that are not
+// diff lines (title, hunk header, help and padding).
+const hunkChromeHeight = 9
+
+var (
+	diffAddStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("42"))
+	diffDelStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+	diffHunkStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("39"))
+	diffMetaStyle = lipgloss.NewStyle().Bold(true)
+)
+
+// hunkDecision records what the user chose for a hunk.
+type hunkDecision int
+
+const (
+	hunkUndecided hunkDecision = iota
+	hunkStage
+	hunkSkip
+)
+
+// hunkEntry is a hunk of the hunk staging step.
+type hunkEntry struct {
+	hunk     git.Hunk
+	decision hunkDecision
+}
+
+// enterHunks switches to the hunk staging step for the given path.
+func (m Model) enterHunks(path string) Model {
+	diff, err := git.GetUnstagedDiff(path)
+	if err != nil {
+		m.stagingErr = err
+		return m
+	}
+	if len(diff.Hunks) == 0 {
+		m.stagingErr = fmt.Errorf("%s has no hunks to stage", path)
+		return m
+	}
+
+	m.step = StepHunks
+	m.hunkDiff = diff
+	m.hunks = make([]hunkEntry, 0, len(diff.Hunks))
+	for _, hunk := range diff.Hunks {
+		m.hunks = append(m.hunks, hunkEntry{hunk: hunk})
+	}
+	m.hunkCursor = 0
+	m.hunkScroll = 0
+	return m
+}
+
+func (m Model) updateHunks(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "y":
+		m.hunks[m.hunkCursor].decision = hunkStage
+		return m.nextHunk()
+
+	case "n":
+		m.hunks[m.hunkCursor].decision = hunkSkip
+		return m.nextHunk()
+
+	case "a", "d":
+		decision := hunkStage
+		if msg.String() == "d" {
+			decision = hunkSkip
+		}
+		for i := m.hunkCursor; i < len(m.hunks); i++ {
+			m.hunks[i].decision = decision
+		}
+		return m.applyHunks()
+
+	case "s":
+		split := m.hunks[m.hunkCursor].hunk.Split()
+		if len(split) > 1 {
+			entries := make([]hunkEntry, 0, len(m.hunks)+len(split)-1)
+			entries = append(entries, m.hunks[:m.hunkCursor]...)
+			for _, hunk := range split {
+				entries = append(entries, hunkEntry{hunk: hunk})
+			}
+			entries = append(entries, m.hunks[m.hunkCursor+1:]...)
+			m.hunks = entries
+			m.hunkScroll = 0
+		}
+
+	case "b":
+		if m.hunkCursor > 0 {
+			m.hunkCursor--
+			m.hunkScroll = 0
+		}
+
+	case "up":
+		if m.hunkScroll > 0 {
+			m.hunkScroll--
+		}
+
+	case "down":
+		if m.hunkScroll < len(m.hunks[m.hunkCursor].hunk.Lines)-1 {
+			m.hunkScroll++
+		}
+
+	case "esc":
+		// Leave without staging anything
+		return m.enterStaging(), nil
+	}
+
+	return m, nil
+}
+
+func (m Model) nextHunk() (tea.Model, tea.Cmd) {
+	if m.hunkCursor < len(m.hunks)-1 {
+		m.hunkCursor++
+		m.hunkScroll = 0
+		return m, nil
+	}
+	return m.applyHunks()
+}
+
+// applyHunks stages the hunks marked for staging and returns to the staging
+// step.
+func (m Model) applyHunks() (tea.Model, tea.Cmd) {
+	var selected []git.Hunk
+	for _, entry := range m.hunks {
+		if entry.decision == hunkStage {
+			selected = append(selected, entry.hunk)
+		}
+	}
+
+	err := git.StageHunks(m.hunkDiff, selected)
+	m = m.invalidatePreview().enterStaging()
+	if err != nil {
+		m.stagingErr = err
+	}
+	return m, nil
+}
+
+func (m Model) hunksView() string {
+	var b strings.Builder
+
+	entry := m.hunks[m.hunkCursor]
+	title := fmt.Sprintf("Stage hunk %d/%d of %s?", m.hunkCursor+1, len(m.hunks), m.hunkDiff.Path)
+	b.WriteString(titleStyle.Render(title) + "\n\n")
+	b.WriteString(renderDiffLine(entry.hunk.Header()) + "\n")
+
+	lines := entry.hunk.Lines[m.hunkScroll:]
+	height := m.height - hunkChromeHeight
+	if height > 0 && len(lines) > height {
+		lines = lines[:height]
+	}
+	for _, line := range lines {
+		b.WriteString(renderDiffLine(line) + "\n")
+	}
+
+	help := "y: stage • n: skip • s: split • a: stage rest • d: skip rest • b: previous • esc: cancel"
+	b.WriteString("\n" + promptStyle.Render(help))
+	return b.String()
+}
+
+// renderDiffLine colours a line of a unified diff.
+func renderDiffLine(line string) string {
+	switch {
+	case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"),
+		strings.HasPrefix(line, "diff "), strings.HasPrefix(line, "index "):
+		return diffMetaStyle.Render(line)
+	case strings.HasPrefix(line, "@@"):
+		return diffHunkStyle.Render(line)
+	case strings.HasPrefix(line, "+"):
+		return diffAddStyle.Render(line)
+	case strings.HasPrefix(line, "-"):
+		return diffDelStyle.Render(line)
+	default:
+		return line
+	}
+}
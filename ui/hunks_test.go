@@ -0,0 +1,61 @@
+package ui
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestHunkStagingStep(t *testing.T) {
+	original := "one\ntwo\nthree\nfour\nfive\nsix\nseven\neight\nnine\n"
+	setupRepo(t, repoOptions{files: map[string]string{"file.txt": original}})
+	runGit(t, "commit", "-q", "-m", "init")
+
+	modified := strings.Replace(strings.Replace(original, "two", "TWO", 1), "eight", "EIGHT", 1)
+	if err := os.WriteFile("file.txt", []byte(modified), 0644); err != nil {
+		t.Fatalf("Failed to modify file: %v", err)
+	}
+
+	model := NewModel(Options{Stage: true})
+	newModel, _ := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'p'}})
+	model = newModel.(Model)
+	if model.step != StepHunks {
+		t.Fatalf("Expected StepHunks (%d), got %d", StepHunks, model.step)
+	}
+	if len(model.hunks) != 1 {
+		t.Fatalf("Expected 1 hunk, got %d", len(model.hunks))
+	}
+	if view := model.View(); !strings.Contains(view, "Stage hunk 1/1") {
+		t.Errorf("Expected hunk title in view, got %q", view)
+	}
+
+	// Split, skip the first change and stage the second
+	for _, r := range []rune{'s', 'n', 'y'} {
+		newModel, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		model = newModel.(Model)
+	}
+
+	if model.step != StepStage {
+		t.Errorf("Expected StepStage (%d) after the last hunk, got %d", StepStage, model.step)
+	}
+
+	output, err := exec.Command("git", "show", ":file.txt").Output()
+	if err != nil {
+		t.Fatalf("Failed to read index: %v", err)
+	}
+	expected := strings.Replace(original, "eight", "EIGHT", 1)
+	if string(output) != expected {
+		t.Errorf("Expected index content %q, got %q", expected, string(output))
+	}
+}
+
+func TestRenderDiffLine(t *testing.T) {
+	for _, line := range []string{"+added", "-removed", "@@ -1 +1 @@", " context", "+++ b/file"} {
+		if !strings.Contains(renderDiffLine(line), line) {
+			t.Errorf("Expected rendered line to contain %q", line)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package ui
+
+import (
+	"errors"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/denysvitali/git-cc/pkg/git"
+)
+
+// enterIdentity switches to the identity step, which sets user.name and
+// user.email for this repository before retrying the commit.
+func (m Model) enterIdentity() (tea.Model, tea.Cmd) {
+	name, email := git.Identity(m.commitOpts.Dir)
+
+	m.identityName = textinput.New()
+	m.identityName.Placeholder = "Your Name"
+	m.identityName.Width = 40
+	m.identityName.SetValue(name)
+
+	m.identityEmail = textinput.New()
+	m.identityEmail.Placeholder = "you@example.com"
+	m.identityEmail.Width = 40
+	m.identityEmail.SetValue(email)
+
+	m.identityErr = nil
+	m.step = StepIdentity
+	m.identityName.Focus()
+	return m, textinput.Blink
+}
+
+func (m Model) updateIdentity(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.step = StepError
+		return m, nil
+
+	case "tab", "shift+tab", "up", "down":
+		return m.toggleIdentityField(), textinput.Blink
+
+	case "enter":
+		if m.identityName.Focused() {
+			return m.toggleIdentityField(), textinput.Blink
+		}
+		return m.applyIdentity()
+	}
+
+	var cmd tea.Cmd
+	if m.identityName.Focused() {
+		m.identityName, cmd = m.identityName.Update(msg)
+	} else {
+		m.identityEmail, cmd = m.identityEmail.Update(msg)
+	}
+	return m, cmd
+}
+
+func (m Model) toggleIdentityField() Model {
+	if m.identityName.Focused() {
+		m.identityName.Blur()
+		m.identityEmail.Focus()
+	} else {
+		m.identityEmail.Blur()
+		m.identityName.Focus()
+	}
+	return m
+}
+
+// applyIdentity stores the identity in the repository config and commits
+// again with the same message.
+func (m Model) applyIdentity() (tea.Model, tea.Cmd) {
+	if m.identityName.Value() == "" || m.identityEmail.Value() == "" {
+		m.identityErr = errors.New("both name and email are required")
+		return m, nil
+	}
+
+	if err := git.SetIdentity(m.commitOpts.Dir, m.identityName.Value(), m.identityEmail.Value()); err != nil {
+		m.identityErr = err
+		return m, nil
+	}
+
+	m.showError = false
+	m.recoveryNote = ""
+	return m.startCommit()
+}
+
+func (m Model) identityView() string {
+	s := titleStyle.Render("Set your identity for this repository") + "\n\n"
+	s += "Name:  " + m.identityName.View() + "\n"
+	s += "Email: " + m.identityEmail.View() + "\n\n"
+	if m.identityErr != nil {
+		s += errorStyle.Render(m.identityErr.Error()) + "\n\n"
+	}
+	s += promptStyle.Render("tab: switch field • enter: save and retry • esc: back")
+	return s
+}
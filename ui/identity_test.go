@@ -0,0 +1,61 @@
+package ui
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/denysvitali/git-cc/pkg/git"
+)
+
+func TestIdentityFix(t *testing.T) {
+	setupRepo(t, repoOptions{})
+
+	model := InitialModel()
+	model.step = StepError
+	model.showError = true
+	model.commitMsg = "feat: identity"
+	model.gitResult = &git.CommitResult{
+		Type:    git.ErrorTypeIdentityMissing,
+		Message: "Your git identity is not configured",
+		Hint:    git.ErrorTypeIdentityMissing.Hint(),
+	}
+
+	view := model.View()
+	if !strings.Contains(view, "Hint: "+git.ErrorTypeIdentityMissing.Hint()) || !strings.Contains(view, "i: set identity") {
+		t.Errorf("Expected hint and fix in view, got %q", view)
+	}
+
+	newModel, _ := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'i'}})
+	model = newModel.(Model)
+	if model.step != StepIdentity {
+		t.Fatalf("Expected StepIdentity (%d), got %d", StepIdentity, model.step)
+	}
+	if model.identityName.Value() != "Test User" {
+		t.Errorf("Expected the current name to be prefilled, got %q", model.identityName.Value())
+	}
+
+	model.identityName.SetValue("Jane Doe")
+	newModel, _ = model.Update(tea.KeyMsg{Type: tea.KeyTab})
+	model = newModel.(Model)
+	model.identityEmail.SetValue("")
+	newModel, _ = model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model = newModel.(Model)
+	if model.step != StepIdentity || model.identityErr == nil {
+		t.Fatal("Expected an error for a missing email")
+	}
+
+	model.identityEmail.SetValue("jane@example.com")
+	newModel, _ = model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model = finishCommit(t, newModel.(Model))
+	if model.gitResult == nil || !model.gitResult.Success {
+		t.Fatalf("Expected retry to succeed, got %+v", model.gitResult)
+	}
+
+	output, _ := exec.Command("git", "log", "-1", "--format=%an <%ae>").Output()
+	if strings.TrimSpace(string(output)) != "Jane Doe <jane@example.com>" {
+		t.Errorf("Expected commit by the new identity, got %q", output)
+	}
+}
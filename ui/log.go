@@ -0,0 +1,404 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/denysvitali/git-cc/pkg/conventional"
+	"github.com/denysvitali/git-cc/pkg/git"
+)
+
+// logChromeHeight is the number of lines of the log that are not part of
+// the list or the detail viewport (filter line, help and padding).
+const logChromeHeight = 3
+
+// logDateLayout is the layout of dates in the log and in date filters.
+const logDateLayout = "2006-01-02"
+
+// typeColors are the colors of the commit types in the log. Other types use
+// otherTypeColor.
+var typeColors = map[string]lipgloss.Color{
+	"feat":     "42",
+	"fix":      "214",
+	"docs":     "39",
+	"style":    "177",
+	"refactor": "141",
+	"perf":     "208",
+	"test":     "226",
+	"build":    "110",
+	"ci":       "110",
+	"chore":    "245",
+}
+
+const otherTypeColor = lipgloss.Color("250")
+
+var (
+	logMetaStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("244"))
+	logBreakingStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Bold(true)
+
+	filterKey = key.NewBinding(key.WithKeys("/"), key.WithHelp("/", "filter"))
+	detailKey = key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "details"))
+)
+
+// logCommit is a commit in the log with its parsed message.
+type logCommit struct {
+	entry git.LogEntry
+	msg   conventional.Message
+	// conventional is false if the header does not follow the specification.
+	conventional bool
+}
+
+func newLogCommit(entry git.LogEntry) logCommit {
+	msg, err := conventional.Parse(entry.Message)
+	return logCommit{entry: entry, msg: msg, conventional: err == nil}
+}
+
+func (c logCommit) FilterValue() string { return c.msg.Header() }
+
+// header renders the header of the commit, colored by type. Breaking changes
+// are highlighted and headers that are not conventional are flagged.
+func (c logCommit) header() string {
+	if !c.conventional {
+		return errorStyle.Render("✗") + " " + logMetaStyle.Render(c.msg.Subject)
+	}
+
+	color, ok := typeColors[c.msg.Type]
+	if !ok {
+		color = otherTypeColor
+	}
+	prefix := c.msg.Type
+	if c.msg.Scope != "" {
+		prefix += "(" + c.msg.Scope + ")"
+	}
+	prefix = lipgloss.NewStyle().Foreground(color).Render(prefix)
+	if c.msg.IsBreaking() {
+		prefix += logBreakingStyle.Render("!")
+	}
+	return "  " + prefix + ": " + c.msg.Subject
+}
+
+// line renders the commit on one line of the log.
+func (c logCommit) line() string {
+	return fmt.Sprintf("%s %s %s %s", logMetaStyle.Render(git.ShortSHA(c.entry.SHA)),
+		logMetaStyle.Render(c.entry.Date.Format(logDateLayout)), c.header(),
+		logMetaStyle.Render("— "+c.entry.AuthorName))
+}
+
+type logDelegate struct{}
+
+func (d logDelegate) Render(w io.Writer, m list.Model, index int, li list.Item) {
+	commit, ok := li.(logCommit)
+	if !ok {
+		return
+	}
+	if index == m.Cursor() {
+		_, _ = io.WriteString(w, selectedItemStyle.Render("❯ "))
+	} else {
+		_, _ = io.WriteString(w, "  ")
+	}
+	_, _ = io.WriteString(w, commit.line())
+}
+
+func (d logDelegate) Height() int                             { return 1 }
+func (d logDelegate) Spacing() int                            { return 0 }
+func (d logDelegate) Update(_ tea.Msg, _ *list.Model) tea.Cmd { return nil }
+
+var _ list.ItemDelegate = logDelegate{}
+
+// logFilter selects the commits shown in the log. Every condition given must
+// match.
+type logFilter struct {
+	types   []string
+	scopes  []string
+	authors []string
+	// since and until bound the author date; until is exclusive.
+	since time.Time
+	until time.Time
+	// words must all appear in the header.
+	words []string
+}
+
+// parseLogFilter parses a filter query such as
+// "type:feat,fix scope:ui author:ann since:2024-01-01 until:2024-06-30 crash".
+// Words without a key are searched in the header. Dates are inclusive.
+func parseLogFilter(query string) (logFilter, error) {
+	var f logFilter
+	for _, field := range strings.Fields(query) {
+		name, value, _ := strings.Cut(field, ":")
+		switch name {
+		case "type":
+			f.types = append(f.types, strings.Split(strings.ToLower(value), ",")...)
+		case "scope":
+			f.scopes = append(f.scopes, strings.Split(strings.ToLower(value), ",")...)
+		case "author":
+			f.authors = append(f.authors, strings.ToLower(value))
+		case "since", "until":
+			date, err := time.ParseInLocation(logDateLayout, value, time.Local)
+			if err != nil {
+				return logFilter{}, fmt.Errorf("invalid date %q for %s, expected YYYY-MM-DD", value, name)
+			}
+			if name == "since" {
+				f.since = date
+			} else {
+				f.until = date.AddDate(0, 0, 1)
+			}
+		default:
+			f.words = append(f.words, strings.ToLower(field))
+		}
+	}
+	return f, nil
+}
+
+// matches reports whether the commit passes the filter.
+func (f logFilter) matches(c logCommit) bool {
+	if len(f.types) > 0 && !slices.Contains(f.types, strings.ToLower(c.msg.Type)) {
+		return false
+	}
+	if len(f.scopes) > 0 && !slices.Contains(f.scopes, strings.ToLower(c.msg.Scope)) {
+		return false
+	}
+	author := strings.ToLower(c.entry.AuthorName + " <" + c.entry.AuthorEmail + ">")
+	for _, name := range f.authors {
+		if !strings.Contains(author, name) {
+			return false
+		}
+	}
+	if !f.since.IsZero() && c.entry.Date.Before(f.since) {
+		return false
+	}
+	if !f.until.IsZero() && !c.entry.Date.Before(f.until) {
+		return false
+	}
+	header := strings.ToLower(c.msg.Header())
+	for _, word := range f.words {
+		if !strings.Contains(header, word) {
+			return false
+		}
+	}
+	return true
+}
+
+// LogModel browses the history of the current branch, parsing each commit
+// as a conventional commit.
+type LogModel struct {
+	commits []logCommit
+	list    list.Model
+	query   string
+
+	filterInput textinput.Model
+	filtering   bool
+	filterErr   error
+
+	// detail is the commit whose full message is shown, if any.
+	detail     *logCommit
+	detailView viewport.Model
+
+	width  int
+	height int
+}
+
+// NewLogModel returns a LogModel listing entries that match the filter
+// query. It returns an error if the query is invalid.
+func NewLogModel(entries []git.LogEntry, query string) (LogModel, error) {
+	m := LogModel{
+		commits:     make([]logCommit, len(entries)),
+		filterInput: textinput.New(),
+		detailView:  viewport.New(0, 0),
+	}
+	for i, entry := range entries {
+		m.commits[i] = newLogCommit(entry)
+	}
+
+	m.list = list.New(nil, logDelegate{}, 0, 0)
+	m.list.SetFilteringEnabled(false)
+	m.list.SetShowHelp(true)
+	m.list.SetStatusBarItemName("commit", "commits")
+	m.list.AdditionalShortHelpKeys = func() []key.Binding {
+		return []key.Binding{filterKey, detailKey}
+	}
+	m.filterInput.Prompt = "/ "
+	m.filterInput.Placeholder = "type:feat,fix scope:ui author:ann since:2024-01-01 until:2024-06-30 words"
+
+	var err error
+	m, err = m.applyFilter(query)
+	return m, err
+}
+
+// applyFilter shows the commits matching query.
+func (m LogModel) applyFilter(query string) (LogModel, error) {
+	filter, err := parseLogFilter(query)
+	if err != nil {
+		return m, err
+	}
+
+	var items []list.Item
+	for _, commit := range m.commits {
+		if filter.matches(commit) {
+			items = append(items, commit)
+		}
+	}
+	m.list.SetItems(items)
+	m.list.ResetSelected()
+
+	m.query = strings.TrimSpace(query)
+	m.list.Title = "Commits"
+	if m.query != "" {
+		m.list.Title += " matching " + m.query
+	}
+	return m, nil
+}
+
+func (m LogModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m LogModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		h, v := appStyle.GetFrameSize()
+		m.width = msg.Width - h
+		m.height = msg.Height - v
+		m.list.SetSize(m.width, m.height-logChromeHeight)
+		m.detailView.Width = m.width
+		m.detailView.Height = m.height - logChromeHeight
+		m.filterInput.Width = m.width - len(m.filterInput.Prompt)
+		return m, nil
+
+	case tea.KeyMsg:
+		if msg.String() == "ctrl+c" {
+			return m, tea.Quit
+		}
+		if m.filtering {
+			return m.updateFilter(msg)
+		}
+		if m.detail != nil {
+			return m.updateDetail(msg)
+		}
+
+		switch msg.String() {
+		case "q":
+			return m, tea.Quit
+		case "esc":
+			if m.query == "" {
+				return m, tea.Quit
+			}
+			m, _ = m.applyFilter("")
+			return m, nil
+		case "/":
+			m.filtering = true
+			m.filterErr = nil
+			m.filterInput.SetValue(m.query)
+			m.filterInput.CursorEnd()
+			return m, m.filterInput.Focus()
+		case "enter":
+			if commit, ok := m.list.SelectedItem().(logCommit); ok {
+				return m.showDetail(commit), nil
+			}
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.list, cmd = m.list.Update(msg)
+	return m, cmd
+}
+
+// updateFilter edits the filter query, applying it on Enter.
+func (m LogModel) updateFilter(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		filtered, err := m.applyFilter(m.filterInput.Value())
+		if err != nil {
+			m.filterErr = err
+			return m, nil
+		}
+		m = filtered
+		m.filtering = false
+		m.filterErr = nil
+		m.filterInput.Blur()
+		return m, nil
+	case "esc":
+		m.filtering = false
+		m.filterErr = nil
+		m.filterInput.Blur()
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.filterInput, cmd = m.filterInput.Update(msg)
+	return m, cmd
+}
+
+// showDetail opens the full message and the changed files of commit.
+func (m LogModel) showDetail(commit logCommit) LogModel {
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("commit "+commit.entry.SHA) + "\n")
+	b.WriteString(fmt.Sprintf("Author: %s <%s>\n", commit.entry.AuthorName, commit.entry.AuthorEmail))
+	b.WriteString(fmt.Sprintf("Date:   %s\n\n", commit.entry.Date.Format("Mon Jan 2 15:04:05 2006 -0700")))
+
+	if !commit.conventional {
+		b.WriteString(errorStyle.Render("✗ Not a conventional commit") + "\n\n")
+	} else if change := commit.msg.BreakingChange(); change != "" {
+		b.WriteString(logBreakingStyle.Render("Breaking change: "+change) + "\n\n")
+	}
+	b.WriteString(commit.entry.Message + "\n\n")
+
+	if files, err := git.CommitStats(commit.entry.SHA); err != nil {
+		b.WriteString(errorStyle.Render(err.Error()) + "\n")
+	} else {
+		b.WriteString(renderFileStats(files, len(files)))
+	}
+
+	m.detail = &commit
+	m.detailView.SetContent(b.String())
+	m.detailView.GotoTop()
+	return m
+}
+
+func (m LogModel) updateDetail(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q", "esc", "enter", "backspace":
+		m.detail = nil
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.detailView, cmd = m.detailView.Update(msg)
+	return m, cmd
+}
+
+func (m LogModel) View() string {
+	if m.detail != nil {
+		return appStyle.Render(m.detailView.View() + "\n\n" +
+			promptStyle.Render("↑/↓ pgup/pgdn: scroll • esc: back to the log"))
+	}
+
+	s := m.list.View()
+	if m.filtering {
+		s += "\n" + m.filterInput.View()
+		if m.filterErr != nil {
+			s += "\n" + errorStyle.Render(m.filterErr.Error())
+		}
+	}
+	return appStyle.Render(s)
+}
+
+// PlainView lists the commits matching the filter one per line, for output
+// that is not a terminal.
+func (m LogModel) PlainView() string {
+	var b strings.Builder
+	for _, item := range m.list.Items() {
+		b.WriteString(item.(logCommit).line() + "\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,159 @@
+package ui
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/denysvitali/git-cc/pkg/git"
+)
+
+func logEntries() []git.LogEntry {
+	date := func(value string) time.Time {
+		d, _ := time.ParseInLocation("2006-01-02 15:04", value, time.Local)
+		return d
+	}
+	return []git.LogEntry{
+		{SHA: "c3", AuthorName: "Ann", AuthorEmail: "ann@example.com", Date: date("2024-03-02 09:00"),
+			Message: "fix(ui): crash on resize\n\nBREAKING CHANGE: drops -x"},
+		{SHA: "c2", AuthorName: "Bob", AuthorEmail: "bob@example.com", Date: date("2024-02-15 23:30"),
+			Message: "Update readme"},
+		{SHA: "c1", AuthorName: "Ann", AuthorEmail: "ann@example.com", Date: date("2024-01-10 12:00"),
+			Message: "feat(parser): support footers"},
+	}
+}
+
+func TestLogFilter(t *testing.T) {
+	tests := map[string][]string{
+		"":                                       {"c3", "c2", "c1"},
+		"type:fix,feat":                          {"c3", "c1"},
+		"scope:UI":                               {"c3"},
+		"author:bob@":                            {"c2"},
+		"since:2024-02-15":                       {"c3", "c2"},
+		"until:2024-02-15":                       {"c2", "c1"},
+		"since:2024-01-01 until:2024-01-31 feat": {"c1"},
+		"author:ann readme":                      nil,
+	}
+
+	for query, expected := range tests {
+		filter, err := parseLogFilter(query)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", query, err)
+			continue
+		}
+		var shas []string
+		for _, entry := range logEntries() {
+			if filter.matches(newLogCommit(entry)) {
+				shas = append(shas, entry.SHA)
+			}
+		}
+		if strings.Join(shas, " ") != strings.Join(expected, " ") {
+			t.Errorf("%q: expected %v, got %v", query, expected, shas)
+		}
+	}
+
+	if _, err := parseLogFilter("since:yesterday"); err == nil {
+		t.Error("Expected an error for an invalid date")
+	}
+}
+
+func TestLogModelFilter(t *testing.T) {
+	model, err := NewLogModel(logEntries(), "author:ann")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(model.list.Items()) != 2 || !strings.Contains(model.list.Title, "author:ann") {
+		t.Fatalf("Expected the 2 commits by Ann, got %d, %q", len(model.list.Items()), model.list.Title)
+	}
+
+	plain := model.PlainView()
+	if !strings.Contains(plain, "fix(ui)!: crash on resize") || !strings.Contains(plain, "— Ann") {
+		t.Errorf("Expected the breaking fix to be listed, got %q", plain)
+	}
+
+	newModel, _ := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'/'}})
+	model = newModel.(LogModel)
+	if !model.filtering || model.filterInput.Value() != "author:ann" {
+		t.Fatalf("Expected to edit the current filter, got %q", model.filterInput.Value())
+	}
+
+	model.filterInput.SetValue("since:soon")
+	newModel, _ = model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model = newModel.(LogModel)
+	if !model.filtering || model.filterErr == nil || !strings.Contains(model.View(), "invalid date") {
+		t.Errorf("Expected an invalid date to be reported, got %v", model.filterErr)
+	}
+
+	model.filterInput.SetValue("readme")
+	newModel, _ = model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model = newModel.(LogModel)
+	if model.filtering || len(model.list.Items()) != 1 {
+		t.Fatalf("Expected the readme commit alone, got %d", len(model.list.Items()))
+	}
+	if plain := model.PlainView(); !strings.Contains(plain, "✗ Update readme") {
+		t.Errorf("Expected the commit to be flagged as not conventional, got %q", plain)
+	}
+
+	// Esc clears the filter before quitting
+	newModel, _ = model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	model = newModel.(LogModel)
+	if model.query != "" || len(model.list.Items()) != 3 {
+		t.Errorf("Expected the filter to be cleared, got %q with %d commits", model.query, len(model.list.Items()))
+	}
+
+	if _, err := NewLogModel(logEntries(), "until:later"); err == nil {
+		t.Error("Expected an error for an invalid query")
+	}
+}
+
+func TestLogModelDetail(t *testing.T) {
+	setupRepo(t, repoOptions{})
+	commit := exec.Command("git", "commit", "-q", "-m", "feat(io)!: add a\n\nExplain why.")
+	if err := commit.Run(); err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+	os.WriteFile("a.txt", []byte("a\nb\n"), 0644)
+	if err := exec.Command("git", "commit", "-q", "-am", "tweak a").Run(); err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+
+	entries, err := git.Log(0)
+	if err != nil {
+		t.Fatalf("Failed to list commits: %v", err)
+	}
+	model, err := NewLogModel(entries, "type:feat")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	newModel, _ := model.Update(tea.WindowSizeMsg{Width: 100, Height: 30})
+	newModel, _ = newModel.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model = newModel.(LogModel)
+	if model.detail == nil {
+		t.Fatal("Expected the commit details to open")
+	}
+
+	view := model.View()
+	for _, expected := range []string{"Author: Test User <test@example.com>", "Breaking change: add a",
+		"Explain why.", "a.txt", "1 file changed, 1 insertion(+), 0 deletions(-)"} {
+		if !strings.Contains(view, expected) {
+			t.Errorf("Expected %q in the details, got %q", expected, view)
+		}
+	}
+
+	newModel, _ = model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	model = newModel.(LogModel)
+	if model.detail != nil {
+		t.Error("Expected Esc to return to the log")
+	}
+	_, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'q'}})
+	if cmd == nil {
+		t.Fatal("Expected q to quit")
+	}
+	if _, ok := cmd().(tea.QuitMsg); !ok {
+		t.Error("Expected q to quit")
+	}
+}
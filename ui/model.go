@@ -2,15 +2,25 @@
 package ui
 
 import (
+	"context"
 	"fmt"
 	"io"
+	"time"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
+	"github.com/denysvitali/git-cc/pkg/conventional"
+	"github.com/denysvitali/git-cc/pkg/draft"
 	"github.com/denysvitali/git-cc/pkg/git"
+	"github.com/denysvitali/git-cc/pkg/history"
+	"github.com/denysvitali/git-cc/pkg/message"
 )
 
 type item struct {
@@ -22,13 +32,119 @@ func (i item) Title() string       { return i.commitType }
 func (i item) Description() string { return i.description }
 func (i item) FilterValue() string { return i.commitType + " " + i.description }
 
+// Options configures the behavior of the Model.
+type Options struct {
+	// CommitArgs are extra arguments passed to git commit.
+	CommitArgs []string
+	// DryRun builds and validates the message without committing it.
+	DryRun bool
+	// Stage opens the staging step before type selection.
+	Stage bool
+	// AllowNoVerify offers to retry with --no-verify after a hook failed.
+	AllowNoVerify bool
+	// Timeout limits how long a commit and its hooks may run.
+	Timeout time.Duration
+	// Draft saves the message in progress and offers to restore it on the
+	// next run.
+	Draft bool
+	// HistorySize is how many committed messages are kept for recall. Zero
+	// disables the history.
+	HistorySize int
+	// Submodules offers to commit inside submodules with staged changes
+	// before bumping them.
+	Submodules bool
+	// ProtectedBranches are glob patterns of branches that should not be
+	// committed to directly.
+	ProtectedBranches []string
+	// BlockProtectedBranches refuses commits to protected branches instead of
+	// warning about them.
+	BlockProtectedBranches bool
+	// BlockDetachedHead stops commits on a detached HEAD on the error step,
+	// where they can be confirmed. Otherwise they are only warned about.
+	BlockDetachedHead bool
+	// Summary shows the new commit, its changed files and the upstream
+	// status after a successful commit instead of quitting right away.
+	Summary bool
+	// SummaryDelay closes the summary once it elapsed. Zero waits for a key
+	// press.
+	SummaryDelay time.Duration
+}
+
 type Model struct {
+	opts      Options
+	started   int
 	list      list.Model
 	scope     textinput.Model
 	message   textinput.Model
+	body      textarea.Model
 	step      int
+	commitMsg string
 	gitResult *git.CommitResult
 	showError bool
+	width     int
+	height    int
+
+	stagingFiles  []stagingEntry
+	stagingCursor int
+	stagingErr    error
+
+	hunkDiff   *git.FileDiff
+	hunks      []hunkEntry
+	hunkCursor int
+	hunkScroll int
+
+	preview       viewport.Model
+	showPreview   bool
+	previewLoaded bool
+
+	bucketFiles  []string
+	bucketOf     []int
+	bucketCursor int
+	bucketErr    error
+	split        *splitSession
+
+	spinner      spinner.Model
+	outputView   viewport.Model
+	output       []string
+	outputChan   chan string
+	commitOpts   git.CommitOptions
+	cancelCommit context.CancelFunc
+	cancelling   bool
+
+	commitHashes  map[string]string
+	recoveryNote  string
+	allowDetached bool
+
+	identityName  textinput.Model
+	identityEmail textinput.Model
+	identityErr   error
+
+	draft *draft.Draft
+
+	history      []history.Entry
+	historyIndex int
+	historyStash *draft.Draft
+	historyList  list.Model
+
+	submodules *submoduleSession
+
+	// operation is the merge, rebase or cherry-pick in progress, if any.
+	operation    string
+	conflicts    []string
+	conflictsErr error
+
+	// protectedBranch is the protected branch HEAD was on at the start.
+	protectedBranch string
+	// newBranch creates a branch named after the commit before committing.
+	newBranch     bool
+	createdBranch string
+
+	// submitErr is why the message could not be committed, such as a lint
+	// failure.
+	submitErr error
+
+	// summary describes the commit once it succeeded.
+	summary *commitSummary
 }
 
 const (
@@ -36,8 +152,23 @@ const (
 	StepScope
 	StepMessage
 	StepError
+	StepStage
+	StepHunks
+	StepBuckets
+	StepSplitSummary
+	StepCommitting
+	StepIdentity
+	StepBody
+	StepRestoreDraft
+	StepHistory
+	StepSubmodules
+	StepConflicts
+	StepProtectedBranch
+	StepSummary
 )
 
+const typeSelectTitle = "Select the type of change"
+
 const (
 	paddingVertical   = 1
 	paddingHorizontal = 2
@@ -62,6 +193,12 @@ var (
 	selectedItemStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
 )
 
+var (
+	stageKey   = key.NewBinding(key.WithKeys("s"), key.WithHelp("s", "stage files"))
+	splitKey   = key.NewBinding(key.WithKeys("m"), key.WithHelp("m", "split into commits"))
+	previewKey = key.NewBinding(key.WithKeys("ctrl+p"), key.WithHelp("ctrl+p", "staged diff"))
+)
+
 type itemListDelegate struct{}
 
 func (i itemListDelegate) Render(w io.Writer, m list.Model, index int, li list.Item) {
@@ -94,43 +231,84 @@ func (i itemListDelegate) Update(_ tea.Msg, _ *list.Model) tea.Cmd {
 
 var _ list.ItemDelegate = itemListDelegate{}
 
+// InitialModel returns a Model with default options.
 func InitialModel() Model {
-	items := []list.Item{
-		item{commitType: "feat", description: "A new feature"},
-		item{commitType: "fix", description: "A bug fix"},
-		item{commitType: "docs", description: "Documentation only changes"},
-		item{commitType: "style", description: "Changes that do not affect the meaning of the code"},
-		item{commitType: "refactor", description: "A code change that neither fixes a bug nor adds a feature"},
-		item{commitType: "perf", description: "A code change that improves performance"},
-		item{commitType: "test", description: "Adding missing tests or correcting existing tests"},
-		item{commitType: "build", description: "Changes that affect the build system or external dependencies"},
-		item{commitType: "ci", description: "Changes to CI configuration files and scripts"},
-		item{commitType: "chore", description: "Other changes that don't modify src or test files"},
+	return NewModel(Options{})
+}
+
+// NewModel returns a Model configured with the given options.
+func NewModel(opts Options) Model {
+	items := make([]list.Item, len(message.Types))
+	for i, t := range message.Types {
+		items[i] = item{commitType: t.Name, description: t.Description}
 	}
 
 	delegate := itemListDelegate{}
 	commitList := list.New(items, delegate, 0, 0)
-	commitList.Title = "Select the type of change"
+	commitList.Title = typeSelectTitle
 	commitList.SetFilteringEnabled(true)
 	commitList.SetShowHelp(true)
+	commitList.AdditionalShortHelpKeys = func() []key.Binding {
+		return []key.Binding{stageKey, splitKey, previewKey}
+	}
 
 	scopeInput := textinput.New()
 	scopeInput.Placeholder = "scope (optional)"
-	scopeInput.CharLimit = 50
+	scopeInput.CharLimit = message.MaxScopeLength
 	scopeInput.Width = 30
 
 	messageInput := textinput.New()
 	messageInput.Placeholder = "commit message"
-	messageInput.CharLimit = 100
+	messageInput.CharLimit = message.MaxSubjectLength
 	messageInput.Width = 50
 
-	return Model{
+	m := Model{
+		opts:      opts,
 		list:      commitList,
 		scope:     scopeInput,
 		message:   messageInput,
+		body:      newBodyInput(),
 		step:      StepTypeSelect,
 		showError: false,
+		preview:   viewport.New(0, 0),
+
+		spinner:    spinner.New(spinner.WithSpinner(spinner.Dot)),
+		outputView: viewport.New(0, 0),
 	}
+
+	m = m.loadHistory()
+	return m.continueStart()
+}
+
+// startSteps are the checks and offers made before the type selection, in
+// order. Each leaves the model on StepTypeSelect or switches to a step of its
+// own, which calls continueStart once the user is done with it.
+var startSteps = []func(Model) Model{
+	Model.checkOperation,
+	Model.checkBranch,
+	Model.offerDraft,
+	Model.offerSubmodules,
+	Model.offerStaging,
+}
+
+// continueStart runs the remaining start steps until one of them switches
+// to a step of its own.
+func (m Model) continueStart() Model {
+	m.step = StepTypeSelect
+	for m.step == StepTypeSelect && m.started < len(startSteps) {
+		m.started++
+		m = startSteps[m.started-1](m)
+	}
+	return m
+}
+
+// offerStaging opens the staging step when nothing is staged, except in a
+// dry run, which leaves the index alone.
+func (m Model) offerStaging() Model {
+	if !m.opts.Stage || m.opts.DryRun {
+		return m
+	}
+	return m.enterStaging()
 }
 
 func (m Model) Init() tea.Cmd {
@@ -138,15 +316,132 @@ func (m Model) Init() tea.Cmd {
 }
 
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	next, cmd := m.update(msg)
+	if nm, ok := next.(Model); ok && nm.step != m.step {
+		switch nm.step {
+		case StepScope, StepMessage, StepBody, StepCommitting:
+			nm.saveDraft()
+		}
+		return nm, cmd
+	}
+	return next, cmd
+}
+
+func (m Model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 	var cmds []tea.Cmd
 
 	switch msg := msg.(type) {
+	case stagedDiffMsg:
+		return m.setStagedDiff(msg), nil
+
+	case commitOutputMsg, commitDoneMsg, spinner.TickMsg:
+		if m.step == StepCommitting {
+			return m.updateCommitting(msg)
+		}
+		return m, nil
+
+	case summaryTimeoutMsg:
+		if m.step == StepSummary {
+			return m, tea.Quit
+		}
+		return m, nil
+
+	case editorDoneMsg:
+		m.recoveryNote = "Files edited. Press 'a' to re-stage them and retry."
+		if msg.err != nil {
+			m.recoveryNote = msg.err.Error()
+		}
+		return m, nil
+
 	case tea.KeyMsg:
+		if m.step == StepCommitting {
+			return m.updateCommitting(msg)
+		}
+		if m.step == StepSummary {
+			return m, tea.Quit
+		}
+		if m.step == StepError && m.showError {
+			switch msg.String() {
+			case "a", "n", "e", "i", "u", "d":
+				return m.updateRecovery(msg)
+			}
+		}
+
+		switch msg.String() {
+		case "ctrl+p":
+			return m.togglePreview()
+		case "esc":
+			if m.showPreview {
+				return m.togglePreview()
+			}
+		}
+		if m.showPreview && msg.String() != "ctrl+c" {
+			return m.updatePreview(msg)
+		}
+
+		if m.step == StepStage && msg.String() != "ctrl+c" {
+			return m.updateStaging(msg)
+		}
+		if m.step == StepHunks && msg.String() != "ctrl+c" {
+			return m.updateHunks(msg)
+		}
+		if m.step == StepBuckets && msg.String() != "ctrl+c" {
+			return m.updateBuckets(msg)
+		}
+		if m.step == StepIdentity && msg.String() != "ctrl+c" {
+			return m.updateIdentity(msg)
+		}
+		if m.step == StepBody && msg.String() != "ctrl+c" {
+			return m.updateBody(msg)
+		}
+		if m.step == StepRestoreDraft && msg.String() != "ctrl+c" {
+			return m.updateRestoreDraft(msg)
+		}
+		if m.step == StepSubmodules && msg.String() != "ctrl+c" {
+			return m.updateSubmodules(msg)
+		}
+		if m.step == StepConflicts && msg.String() != "ctrl+c" {
+			return m.updateConflicts(msg)
+		}
+		if m.step == StepProtectedBranch && msg.String() != "ctrl+c" {
+			return m.updateProtectedBranch(msg)
+		}
+		if m.step == StepHistory && msg.String() != "ctrl+c" {
+			return m.updateHistory(msg)
+		}
+		if m.step == StepMessage {
+			switch msg.String() {
+			case "tab":
+				return m.enterBody()
+			case "up":
+				return m.recallHistory(1), nil
+			case "down":
+				return m.recallHistory(-1), nil
+			case "ctrl+r":
+				if len(m.history) > 0 {
+					return m.enterHistory()
+				}
+			}
+		}
+
 		switch msg.String() {
 		case "ctrl+c":
+			m.restoreSplit()
 			return m, tea.Quit
 
+		case "m":
+			if m.step == StepTypeSelect && m.split == nil && m.submodules == nil && m.operation == "" &&
+				!m.opts.DryRun &&
+				m.list.FilterState() != list.Filtering {
+				return m.enterBuckets(), nil
+			}
+
+		case "s":
+			if m.step == StepTypeSelect && m.list.FilterState() != list.Filtering {
+				return m.enterStaging(), nil
+			}
+
 		// Note: 'q' is intentionally NOT handled here
 		// - In StepTypeSelect: it will reach the list update below
 		// - In StepScope/StepMessage: textinput handles it before reaching here
@@ -166,19 +461,9 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, textinput.Blink
 
 			case StepMessage:
-				if m.message.Value() == "" {
-					return m, nil
-				}
-
-				commitMsg := m.buildCommitMessage()
-				m.gitResult = git.CommitWithResult(commitMsg)
-
-				if !m.gitResult.Success {
-					m.step = StepError
-					m.showError = true
-					return m, nil
-				}
+				return m.submitMessage()
 
+			case StepSplitSummary:
 				return m, tea.Quit
 			}
 
@@ -188,6 +473,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.step = StepMessage
 				m.message.Focus()
 				m.showError = false
+				m.recoveryNote = ""
 				return m, textinput.Blink
 			}
 		}
@@ -195,6 +481,15 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.WindowSizeMsg:
 		h, v := appStyle.GetFrameSize()
 		m.list.SetSize(msg.Width-h, msg.Height-v)
+		m.width = msg.Width - h
+		m.height = msg.Height - v
+		if m.step == StepHistory {
+			m.historyList.SetSize(m.width, m.height)
+		}
+		m.preview.Width = msg.Width - h
+		m.preview.Height = msg.Height - v - previewChromeHeight
+		m.outputView.Width = msg.Width - h
+		m.outputView.Height = msg.Height - v - commitChromeHeight
 	}
 
 	switch m.step {
@@ -214,12 +509,20 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case StepMessage:
 		m.message, cmd = m.message.Update(msg)
 		cmds = append(cmds, cmd)
+
+	case StepHistory:
+		m.historyList, cmd = m.historyList.Update(msg)
+		cmds = append(cmds, cmd)
 	}
 
 	return m, tea.Batch(cmds...)
 }
 
 func (m Model) View() string {
+	if m.showPreview {
+		return appStyle.Render(m.previewView())
+	}
+
 	var s string
 
 	switch m.step {
@@ -227,7 +530,8 @@ func (m Model) View() string {
 		s = m.list.View()
 
 	case StepScope:
-		s = titleStyle.Render("Enter scope (optional, press Enter to skip):") + "\n"
+		s = m.splitHeader() + m.submoduleHeader()
+		s += titleStyle.Render("Enter scope (optional, press Enter to skip):") + "\n"
 		s += m.scope.View()
 
 	case StepMessage:
@@ -236,31 +540,139 @@ func (m Model) View() string {
 		if m.scope.Value() != "" {
 			scopeStr = fmt.Sprintf("(%s)", m.scope.Value())
 		}
-		s = titleStyle.Render("Enter commit message:") + "\n"
+		s = m.splitHeader() + m.submoduleHeader()
+		s += titleStyle.Render("Enter commit message:") + "\n"
 		s += promptStyle.Render(fmt.Sprintf("%s%s: ", selectedItem.commitType, scopeStr))
-		s += m.message.View()
+		s += m.message.View() + "\n\n"
+		if m.submitErr != nil {
+			s += errorStyle.Render(m.submitErr.Error()) + "\n\n"
+		}
+		help := "enter: commit • tab: add body"
+		if len(m.history) > 0 {
+			help += " • ↑/↓: previous messages • ctrl+r: search history"
+		}
+		s += promptStyle.Render(help)
+
+	case StepStage:
+		s = m.stagingView()
+
+	case StepHunks:
+		s = m.hunksView()
+
+	case StepBuckets:
+		s = m.bucketsView()
+
+	case StepSplitSummary:
+		s = m.splitSummaryView()
+
+	case StepCommitting:
+		s = m.committingView()
+
+	case StepIdentity:
+		s = m.identityView()
+
+	case StepBody:
+		s = m.bodyView()
+
+	case StepRestoreDraft:
+		s = m.restoreDraftView()
+
+	case StepHistory:
+		s = m.historyList.View()
+
+	case StepSubmodules:
+		s = m.submodulesView()
+
+	case StepConflicts:
+		s = m.conflictsView()
+
+	case StepProtectedBranch:
+		s = m.protectedBranchView()
+
+	case StepSummary:
+		s = m.summaryView()
 
 	case StepError:
-		s = errorStyle.Render("Commit Failed!") + "\n\n"
+		s = m.splitHeader() + m.submoduleHeader() + errorStyle.Render("Commit Failed!") + "\n\n"
 		if m.gitResult != nil {
-			s += m.gitResult.Message + "\n\n"
+			s += m.gitResult.Message + "\n"
+			if status := m.hookStatus(); status != "" {
+				s += status + "\n"
+			}
+			s += "\n"
 			if m.gitResult.Details != "" && m.gitResult.Details != m.gitResult.Message {
 				s += m.gitResult.Details + "\n\n"
 			}
 		}
-		s += promptStyle.Render("Press 'r' to retry or Ctrl+C to quit")
+		if m.gitResult != nil && m.gitResult.Hint != "" {
+			s += "Hint: " + m.gitResult.Hint + "\n\n"
+		}
+		if m.recoveryNote != "" {
+			s += m.recoveryNote + "\n\n"
+		}
+		s += promptStyle.Render(m.recoveryHelp())
 	}
 
 	return appStyle.Render(s)
 }
 
-func (m Model) buildCommitMessage() string {
+// fields returns the parts of the message entered so far.
+func (m Model) fields() conventional.Message {
 	selectedItem := m.list.SelectedItem().(item)
-	scopeStr := ""
-	if m.scope.Value() != "" {
-		scopeStr = fmt.Sprintf("(%s)", m.scope.Value())
+	f := conventional.Message{
+		Type:    selectedItem.commitType,
+		Scope:   m.scope.Value(),
+		Subject: m.message.Value(),
+	}
+	f.Body, f.Footers = conventional.SplitBody(m.bodyText())
+	return f
+}
+
+// buildHeader returns the first line of the commit message.
+func (m Model) buildHeader() string {
+	return m.fields().Header()
+}
+
+func (m Model) buildCommitMessage() string {
+	return m.fields().String()
+}
+
+// submitMessage builds the commit message and commits it, or quits in
+// dry-run mode.
+func (m Model) submitMessage() (tea.Model, tea.Cmd) {
+	if m.message.Value() == "" {
+		return m, nil
+	}
+
+	if err := message.Lint(m.fields()); err != nil {
+		m.submitErr = err
+		return m, nil
+	}
+	m.submitErr = nil
+
+	m.commitMsg = m.buildCommitMessage()
+	if m.opts.DryRun {
+		return m, tea.Quit
 	}
-	return fmt.Sprintf("%s%s: %s", selectedItem.commitType, scopeStr, m.message.Value())
+
+	var err error
+	if m, err = m.createBranch(); err != nil {
+		m.submitErr = err
+		return m, nil
+	}
+	return m.startCommit()
+}
+
+// CommitMessage returns the message built when the user confirmed the commit,
+// or an empty string if the user quit before that.
+func (m Model) CommitMessage() string {
+	return m.commitMsg
+}
+
+// CreatedBranch returns the branch created to avoid committing to a protected
+// branch, or "" if there is none.
+func (m Model) CreatedBranch() string {
+	return m.createdBranch
 }
 
 func (m Model) GetCommitResult() *git.CommitResult {
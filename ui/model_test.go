@@ -1,6 +1,7 @@
 package ui
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/charmbracelet/bubbles/list"
@@ -289,3 +290,44 @@ func TestItemMethods(t *testing.T) {
 		t.Errorf("Expected filter value '%s', got '%s'", expectedFilter, testItem.FilterValue())
 	}
 }
+
+func TestModelUpdate_DryRun(t *testing.T) {
+	model := NewModel(Options{DryRun: true})
+	model.step = StepMessage
+	model.list.SetItems([]list.Item{item{commitType: "feat", description: "A new feature"}})
+	model.list.Select(0)
+	model.message.SetValue("add dry run")
+
+	newModel, cmd := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Error("Expected quit command in dry-run mode")
+	}
+
+	newModelTyped := newModel.(Model)
+	if newModelTyped.CommitMessage() != "feat: add dry run" {
+		t.Errorf("Expected built commit message, got '%s'", newModelTyped.CommitMessage())
+	}
+	if newModelTyped.GetCommitResult() != nil {
+		t.Error("Expected no commit result in dry-run mode")
+	}
+}
+
+func TestModelUpdate_LintFailure(t *testing.T) {
+	model := NewModel(Options{DryRun: true})
+	model.step = StepMessage
+	model.scope.SetValue("a(b)")
+	model.message.SetValue("add lint")
+
+	newModel, cmd := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd != nil {
+		t.Error("Expected no command for an invalid message")
+	}
+
+	model = newModel.(Model)
+	if model.step != StepMessage || model.CommitMessage() != "" {
+		t.Errorf("Expected to stay on the message step, got step %d", model.step)
+	}
+	if view := model.View(); !strings.Contains(view, "parentheses") {
+		t.Errorf("Expected the lint problem in the view, got %q", view)
+	}
+}
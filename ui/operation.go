@@ -0,0 +1,78 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/denysvitali/git-cc/pkg/git"
+	"github.com/denysvitali/git-cc/pkg/history"
+)
+
+// ErrConflicts is returned by RunPlain when a merge, rebase or cherry-pick in
+// progress has unresolved conflicts.
+var ErrConflicts = errors.New("unresolved conflicts")
+
+// checkOperation detects a merge, rebase or cherry-pick in progress. While
+// conflicts remain it stops on the conflicts step; otherwise it fills in the
+// message git prepared, so that it can be turned into a conventional one.
+func (m Model) checkOperation() Model {
+	m.operation = git.OperationInProgress()
+	if m.operation == "" {
+		return m
+	}
+
+	m.conflicts, m.conflictsErr = git.ConflictedFiles()
+	if len(m.conflicts) > 0 || m.conflictsErr != nil {
+		m.step = StepConflicts
+		return m
+	}
+
+	m.step = StepTypeSelect
+	m.list.Title = fmt.Sprintf("%s%s in progress: select the type of change",
+		strings.ToUpper(m.operation[:1]), m.operation[1:])
+
+	message, err := git.OperationMessage()
+	if err != nil || message == "" {
+		return m
+	}
+	return m.applyEntry(history.NewEntry(message))
+}
+
+func (m Model) updateConflicts(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "r", "enter":
+		if m = m.checkOperation(); m.step == StepTypeSelect {
+			m = m.continueStart()
+		}
+
+	case "q", "esc":
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+func (m Model) conflictsView() string {
+	var b strings.Builder
+	b.WriteString(titleStyle.Render(fmt.Sprintf("A %s is in progress with unresolved conflicts", m.operation)) + "\n\n")
+
+	for _, file := range m.conflicts {
+		b.WriteString(fmt.Sprintf("- %s\n", file))
+	}
+	if m.conflictsErr != nil {
+		b.WriteString(errorStyle.Render(m.conflictsErr.Error()) + "\n")
+	}
+
+	b.WriteString("\nResolve the conflicts and stage the files with git add, or run\n")
+	b.WriteString(fmt.Sprintf("git %s --abort to give up.\n", m.operation))
+	b.WriteString("\n" + promptStyle.Render("r: check again • q: quit"))
+	return b.String()
+}
+
+// Conflicted reports whether the run stopped on the unresolved conflicts of a
+// merge, rebase or cherry-pick in progress.
+func (m Model) Conflicted() bool {
+	return m.step == StepConflicts
+}
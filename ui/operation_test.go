@@ -0,0 +1,118 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// setupMergeConflict creates a repository with a merge of branch other
+// stopped on a conflict in a.txt.
+func setupMergeConflict(t *testing.T) {
+	t.Helper()
+
+	setupRepo(t, repoOptions{files: map[string]string{"a.txt": "base\n"}})
+	runGit(t, "commit", "-q", "-m", "base")
+	runGit(t, "checkout", "-q", "-b", "other")
+	writeFile(t, "a.txt", "other\n")
+	runGit(t, "commit", "-q", "-am", "other")
+	runGit(t, "checkout", "-q", "-")
+	writeFile(t, "a.txt", "main\n")
+	runGit(t, "commit", "-q", "-am", "main")
+
+	if err := exec.Command("git", "merge", "other").Run(); err == nil {
+		t.Fatal("Expected the merge to stop on the conflict")
+	}
+}
+
+// writeFile writes content to name and fails the test if it cannot.
+func writeFile(t *testing.T, name, content string) {
+	t.Helper()
+
+	if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", name, err)
+	}
+}
+
+func TestMergeWithConflicts(t *testing.T) {
+	setupMergeConflict(t)
+
+	model := NewModel(Options{})
+	if model.step != StepConflicts {
+		t.Fatalf("Expected StepConflicts (%d), got %d", StepConflicts, model.step)
+	}
+	if view := model.View(); !strings.Contains(view, "a.txt") {
+		t.Errorf("Expected the conflicted file to be listed, got %q", view)
+	}
+	if !model.Conflicted() {
+		t.Error("Expected the run to stop on the conflicts")
+	}
+	if _, err := RunPlain(context.Background(), Options{}, strings.NewReader(""), io.Discard); !errors.Is(err, ErrConflicts) {
+		t.Errorf("Expected ErrConflicts from the plain prompts, got %v", err)
+	}
+
+	// Still conflicted
+	newModel, _ := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'r'}})
+	model = newModel.(Model)
+	if model.step != StepConflicts {
+		t.Fatalf("Expected to stay on StepConflicts, got %d", model.step)
+	}
+
+	writeFile(t, "a.txt", "resolved\n")
+	runGit(t, "add", "a.txt")
+
+	newModel, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'r'}})
+	model = newModel.(Model)
+	if model.step != StepTypeSelect || model.Conflicted() {
+		t.Fatalf("Expected StepTypeSelect (%d), got %d", StepTypeSelect, model.step)
+	}
+	if !strings.HasPrefix(model.list.Title, "Merge in progress") {
+		t.Errorf("Expected the title to mention the merge, got %q", model.list.Title)
+	}
+	if model.message.Value() != "Merge branch 'other'" {
+		t.Errorf("Expected the message from MERGE_MSG, got %q", model.message.Value())
+	}
+
+	model.list.Select(9)
+	newModel, _ = model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	newModel, _ = newModel.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model = newModel.(Model)
+	model.message.SetValue("merge other")
+	newModel, _ = model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	finishCommit(t, newModel.(Model))
+
+	log, _ := exec.Command("git", "log", "-1", "--format=%P %s").Output()
+	parents, subject, _ := strings.Cut(strings.TrimSpace(string(log)), " chore")
+	if len(strings.Fields(parents)) != 2 || subject != ": merge other" {
+		t.Errorf("Expected a merge commit 'chore: merge other', got %q", string(log))
+	}
+}
+
+func TestCherryPickMessagePrefilled(t *testing.T) {
+	setupMergeConflict(t)
+	runGit(t, "merge", "--abort")
+
+	runGit(t, "commit", "-q", "--amend", "--allow-empty", "-m", "main")
+	runGit(t, "checkout", "-q", "other")
+	runGit(t, "commit", "-q", "--amend", "-m", "fix(io): handle other")
+	runGit(t, "checkout", "-q", "-")
+	if err := exec.Command("git", "cherry-pick", "other").Run(); err == nil {
+		t.Fatal("Expected the cherry-pick to stop on the conflict")
+	}
+	writeFile(t, "a.txt", "resolved\n")
+	runGit(t, "add", "a.txt")
+
+	model := NewModel(Options{})
+	if model.step != StepTypeSelect {
+		t.Fatalf("Expected StepTypeSelect (%d), got %d", StepTypeSelect, model.step)
+	}
+	if header := model.buildHeader(); header != "fix(io): handle other" {
+		t.Errorf("Expected the cherry-picked header, got %q", header)
+	}
+}
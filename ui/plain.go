@@ -0,0 +1,257 @@
+package ui
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+
+	"github.com/denysvitali/git-cc/pkg/draft"
+	"github.com/denysvitali/git-cc/pkg/git"
+	"github.com/denysvitali/git-cc/pkg/message"
+)
+
+// ErrAborted is returned by RunPlain when the input ends before the message
+// is complete.
+var ErrAborted = errors.New("aborted")
+
+// ErrNothingStaged is returned by RunPlain when nothing is staged, as changes
+// can only be staged in the full interface.
+var ErrNothingStaged = errors.New("nothing is staged")
+
+// clearAnswer clears an optional field that has a default.
+const clearAnswer = "-"
+
+// bodyEnd is the line that ends the body in plain prompts.
+const bodyEnd = "."
+
+// plainPrompt asks questions line by line.
+type plainPrompt struct {
+	in  *bufio.Scanner
+	out io.Writer
+}
+
+// ask prints the question with its default in brackets and returns the
+// answer, or def if the answer is empty.
+func (p plainPrompt) ask(question, def string) (string, error) {
+	if def != "" {
+		fmt.Fprintf(p.out, "%s [%s]: ", question, def)
+	} else {
+		fmt.Fprintf(p.out, "%s: ", question)
+	}
+	if !p.in.Scan() {
+		fmt.Fprintln(p.out)
+		if err := p.in.Err(); err != nil {
+			return "", err
+		}
+		return "", ErrAborted
+	}
+	if answer := strings.TrimSpace(p.in.Text()); answer != "" {
+		return answer, nil
+	}
+	return def, nil
+}
+
+// confirm asks a yes or no question.
+func (p plainPrompt) confirm(question string, def bool) (bool, error) {
+	choices := "y/N"
+	if def {
+		choices = "Y/n"
+	}
+	for {
+		answer, err := p.ask(fmt.Sprintf("%s (%s)", question, choices), "")
+		if err != nil {
+			return false, err
+		}
+		switch strings.ToLower(answer) {
+		case "":
+			return def, nil
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+	}
+}
+
+// text reads lines until one that holds only bodyEnd, or the end of the
+// input.
+func (p plainPrompt) text(question string) string {
+	fmt.Fprintf(p.out, "%s (end with a line containing only %q):\n", question, bodyEnd)
+	var lines []string
+	for p.in.Scan() && p.in.Text() != bodyEnd {
+		lines = append(lines, p.in.Text())
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// RunPlain asks for the message with line-based prompts on in and out, for
+// terminals that cannot run the full interface, and commits it. The
+// returned Model reports the outcome like the one run by Bubble Tea. An
+// interrupt during the commit cancels it instead of quitting.
+func RunPlain(ctx context.Context, opts Options, in io.Reader, out io.Writer) (Model, error) {
+	// Committing inside submodules needs several messages
+	opts.Submodules = false
+
+	p := plainPrompt{in: bufio.NewScanner(in), out: out}
+	m := NewModel(opts)
+
+	var err error
+	for m.step != StepTypeSelect && err == nil {
+		m, err = m.plainStart(p)
+	}
+	if err != nil {
+		return m, err
+	}
+
+	for {
+		d, err := m.askMessage(p)
+		if err != nil {
+			return m, err
+		}
+		m = m.applyDraft(d)
+		if err := message.Lint(m.fields()); err != nil {
+			fmt.Fprintln(out, err)
+			continue
+		}
+		break
+	}
+
+	m.commitMsg = m.buildCommitMessage()
+	if opts.DryRun {
+		return m, nil
+	}
+
+	if m, err = m.createBranch(); err != nil {
+		return m, err
+	}
+
+	// Ctrl+C at a prompt quits right away, while during the commit it stops
+	// git and its hooks
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	// Only the output of hooks is shown, as the caller prints its own summary
+	m.gitResult = git.CommitWithResult(ctx, m.commitMsg, git.CommitOptions{
+		Args:    append([]string{"--quiet"}, m.opts.CommitArgs...),
+		Output:  out,
+		Timeout: m.opts.Timeout,
+	})
+	if !m.gitResult.Success {
+		m.saveDraft()
+		fmt.Fprintf(out, "Error: %s\n", m.gitResult.Message)
+		if m.gitResult.Hint != "" {
+			fmt.Fprintf(out, "Hint: %s\n", m.gitResult.Hint)
+		}
+		return m, nil
+	}
+	m.clearDraft()
+	return m.recordHistory(), nil
+}
+
+// plainStart answers the step shown before the type selection with prompts.
+func (m Model) plainStart(p plainPrompt) (Model, error) {
+	switch m.step {
+	case StepConflicts:
+		if m.conflictsErr != nil {
+			return m, fmt.Errorf("%w: %w", ErrConflicts, m.conflictsErr)
+		}
+		return m, fmt.Errorf("a %s is in progress with %w in %s",
+			m.operation, ErrConflicts, strings.Join(m.conflicts, ", "))
+
+	case StepProtectedBranch:
+		fmt.Fprintf(p.out, "%s is a protected branch.\n", m.protectedBranch)
+		newBranch, err := p.confirm("Commit on a new branch named after the type and scope?", true)
+		if err != nil {
+			return m, err
+		}
+		if !newBranch && m.opts.BlockProtectedBranches {
+			return m, ErrAborted
+		}
+		m.newBranch = newBranch
+
+	case StepRestoreDraft:
+		fmt.Fprintf(p.out, "A draft was saved by an earlier run:\n\n%s\n\n", m.draftMessage())
+		restore, err := p.confirm("Restore it?", true)
+		if err != nil {
+			return m, err
+		}
+		if restore {
+			m = m.applyDraft(m.draft)
+		} else {
+			m.clearDraft()
+		}
+		m.draft = nil
+
+	case StepStage:
+		return m, fmt.Errorf("%w, stage the changes to commit with git add", ErrNothingStaged)
+	}
+
+	return m.continueStart(), nil
+}
+
+// askMessage asks for each part of the message, offering what was entered or
+// prefilled so far as the defaults.
+func (m Model) askMessage(p plainPrompt) (*draft.Draft, error) {
+	current := m.fields()
+	d := &draft.Draft{}
+
+	names := make([]string, len(message.Types))
+	for i, t := range message.Types {
+		names[i] = t.Name
+	}
+	for {
+		answer, err := p.ask(fmt.Sprintf("Type (%s)", strings.Join(names, ", ")), current.Type)
+		if err != nil {
+			return nil, err
+		}
+		if slices.Contains(names, answer) {
+			d.Type = answer
+			break
+		}
+		fmt.Fprintf(p.out, "Unknown type %q\n", answer)
+	}
+
+	scope, err := p.ask("Scope (optional)", current.Scope)
+	if err != nil {
+		return nil, err
+	}
+	if scope != clearAnswer {
+		d.Scope = scope
+	}
+
+	for d.Subject == "" {
+		if d.Subject, err = p.ask("Subject", current.Subject); err != nil {
+			return nil, err
+		}
+	}
+
+	// The footers, such as trailers of a restored draft, are kept as they are
+	for _, footer := range current.Footers {
+		d.Footers = append(d.Footers, footer.String())
+	}
+	if len(d.Footers) > 0 {
+		fmt.Fprintf(p.out, "Footers:\n%s\n", strings.Join(d.Footers, "\n"))
+	}
+
+	d.Body = current.Body
+	if d.Body != "" {
+		fmt.Fprintf(p.out, "Body:\n%s\n", d.Body)
+		keep, err := p.confirm("Keep this body?", true)
+		if err != nil {
+			return nil, err
+		}
+		if !keep {
+			d.Body = ""
+		}
+	}
+	if d.Body == "" {
+		d.Body = p.text("Body (optional)")
+	}
+	return d, nil
+}
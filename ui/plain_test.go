@@ -0,0 +1,131 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/denysvitali/git-cc/pkg/draft"
+)
+
+func TestRunPlain(t *testing.T) {
+	setupRepo(t, repoOptions{})
+
+	input := strings.Join([]string{
+		"feature", // unknown, asked again
+		"fix",
+		"a(b)", // fails the lint, so the questions are asked again
+		"",     // no subject, asked again
+		"handle errors",
+		"First paragraph.",
+		"",
+		"Second paragraph.",
+		".",
+		"", // keep fix
+		"-",
+		"", // keep the subject
+		"", // keep the body
+	}, "\n") + "\n"
+
+	var out strings.Builder
+	model, err := RunPlain(context.Background(), Options{}, strings.NewReader(input), &out)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v\n%s", err, out.String())
+	}
+	if result := model.GetCommitResult(); result == nil || !result.Success {
+		t.Fatalf("Expected the commit to succeed, got %+v\n%s", result, out.String())
+	}
+
+	for _, expected := range []string{`Unknown type "feature"`, "parentheses", "Subject [handle errors]"} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("Expected %q in the output, got:\n%s", expected, out.String())
+		}
+	}
+
+	log, _ := exec.Command("git", "log", "-1", "--format=%B").Output()
+	expected := "fix: handle errors\n\nFirst paragraph.\n\nSecond paragraph.\n"
+	if strings.TrimSpace(string(log)) != strings.TrimSpace(expected) {
+		t.Errorf("Expected commit message %q, got %q", expected, string(log))
+	}
+}
+
+func TestRunPlainAborted(t *testing.T) {
+	setupRepo(t, repoOptions{})
+
+	var out strings.Builder
+	_, err := RunPlain(context.Background(), Options{DryRun: true}, strings.NewReader("feat\n"), &out)
+	if !errors.Is(err, ErrAborted) {
+		t.Errorf("Expected ErrAborted at the end of the input, got %v", err)
+	}
+}
+
+func TestRunPlainNothingStaged(t *testing.T) {
+	setupRepo(t, repoOptions{})
+	exec.Command("git", "reset", "-q").Run()
+	os.WriteFile("b.txt", []byte("b"), 0644)
+
+	var out strings.Builder
+	_, err := RunPlain(context.Background(), Options{Stage: true}, strings.NewReader(""), &out)
+	if !errors.Is(err, ErrNothingStaged) {
+		t.Errorf("Expected an error about nothing being staged, got %v", err)
+	}
+}
+
+func TestRunPlainDetachedHead(t *testing.T) {
+	setupRepo(t, repoOptions{})
+	if err := os.WriteFile("b.txt", []byte("b"), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	for _, args := range [][]string{{"commit", "-q", "-m", "initial"}, {"checkout", "-q", "--detach"}, {"add", "b.txt"}} {
+		if err := exec.Command("git", args...).Run(); err != nil {
+			t.Fatalf("Failed to run git %v: %v", args, err)
+		}
+	}
+
+	var out strings.Builder
+	model, err := RunPlain(context.Background(), Options{}, strings.NewReader("feat\n\ndetached\n.\n"), &out)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v\n%s", err, out.String())
+	}
+	result := model.GetCommitResult()
+	if result == nil || !result.Success {
+		t.Fatalf("Expected the commit on a detached HEAD to succeed, got %+v\n%s", result, out.String())
+	}
+	if len(result.Warnings) == 0 || !strings.Contains(result.Warnings[len(result.Warnings)-1], "HEAD is detached") {
+		t.Errorf("Expected a detached HEAD warning, got %q", result.Warnings)
+	}
+}
+
+func TestRunPlainKeepsFooters(t *testing.T) {
+	setupRepo(t, repoOptions{})
+	saved := &draft.Draft{Type: "fix", Subject: "handle errors", Body: "It crashed.", Footers: []string{"Refs: #1"}}
+	if err := draft.Save(saved); err != nil {
+		t.Fatalf("Failed to save draft: %v", err)
+	}
+
+	input := strings.Join([]string{
+		"", // restore the draft
+		"", // keep fix
+		"", // no scope
+		"", // keep the subject
+		"", // keep the body
+	}, "\n") + "\n"
+
+	var out strings.Builder
+	model, err := RunPlain(context.Background(), Options{Draft: true}, strings.NewReader(input), &out)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v\n%s", err, out.String())
+	}
+	if result := model.GetCommitResult(); result == nil || !result.Success {
+		t.Fatalf("Expected the commit to succeed, got %+v\n%s", result, out.String())
+	}
+
+	log, _ := exec.Command("git", "log", "-1", "--format=%B").Output()
+	expected := "fix: handle errors\n\nIt crashed.\n\nRefs: #1"
+	if strings.TrimSpace(string(log)) != expected {
+		t.Errorf("Expected commit message %q, got %q", expected, string(log))
+	}
+}
@@ -0,0 +1,88 @@
+package ui
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/denysvitali/git-cc/pkg/git"
+)
+
+// previewChromeHeight is the number of lines of the preview pane that are
+// not part of the viewport (title, help and padding).
+const previewChromeHeight = 4
+
+// stagedDiffMsg carries the staged diff loaded for the preview pane.
+type stagedDiffMsg struct {
+	diff *git.StagedDiff
+	err  error
+}
+
+// loadStagedDiff loads the staged diff in the background.
+func loadStagedDiff() tea.Msg {
+	diff, err := git.GetStagedDiff()
+	return stagedDiffMsg{diff: diff, err: err}
+}
+
+// togglePreview shows or hides the staged diff preview, loading the diff on
+// first use and after the index changed.
+func (m Model) togglePreview() (tea.Model, tea.Cmd) {
+	m.showPreview = !m.showPreview
+	if m.showPreview && !m.previewLoaded {
+		m.preview.SetContent("Loading staged changes...")
+		return m, loadStagedDiff
+	}
+	return m, nil
+}
+
+func (m Model) updatePreview(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	m.preview, cmd = m.preview.Update(msg)
+	return m, cmd
+}
+
+// setStagedDiff renders the loaded diff into the preview viewport.
+func (m Model) setStagedDiff(msg stagedDiffMsg) Model {
+	m.previewLoaded = msg.err == nil
+	if msg.err != nil {
+		m.preview.SetContent(errorStyle.Render(msg.err.Error()))
+		return m
+	}
+	m.preview.SetContent(renderStagedDiff(msg.diff))
+	m.preview.GotoTop()
+	return m
+}
+
+// invalidatePreview marks the preview as stale after the index changed.
+func (m Model) invalidatePreview() Model {
+	m.previewLoaded = false
+	return m
+}
+
+func (m Model) previewView() string {
+	return titleStyle.Render("Staged changes") + "\n" +
+		m.preview.View() + "\n" +
+		promptStyle.Render("↑/↓ pgup/pgdn: scroll • ctrl+p/esc: close")
+}
+
+func renderStagedDiff(diff *git.StagedDiff) string {
+	if diff == nil || len(diff.Files) == 0 {
+		return "No staged changes."
+	}
+
+	var b strings.Builder
+	b.WriteString(diff.Stat + "\n")
+	for _, file := range diff.Files {
+		b.WriteString("\n")
+		for _, line := range file.Header {
+			b.WriteString(renderDiffLine(line) + "\n")
+		}
+		for _, hunk := range file.Hunks {
+			b.WriteString(renderDiffLine(hunk.Header()) + "\n")
+			for _, line := range hunk.Lines {
+				b.WriteString(renderDiffLine(line) + "\n")
+			}
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,79 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/denysvitali/git-cc/pkg/git"
+)
+
+func TestPreviewToggle(t *testing.T) {
+	model := InitialModel()
+	model.step = StepMessage
+
+	ctrlP := tea.KeyMsg{Type: tea.KeyCtrlP}
+	newModel, cmd := model.Update(ctrlP)
+	model = newModel.(Model)
+
+	if !model.showPreview {
+		t.Fatal("Expected preview to be shown")
+	}
+	if cmd == nil {
+		t.Fatal("Expected a command loading the staged diff")
+	}
+
+	diff := &git.StagedDiff{
+		Stat: " file.txt | 1 +",
+		Files: []*git.FileDiff{{
+			Path:   "file.txt",
+			Header: []string{"diff --git a/file.txt b/file.txt"},
+			Hunks:  []git.Hunk{{OldStart: 0, OldLines: 0, NewStart: 1, NewLines: 1, Lines: []string{"+hello"}}},
+		}},
+	}
+	newModel, _ = model.Update(stagedDiffMsg{diff: diff})
+	model = newModel.(Model)
+
+	if !model.previewLoaded {
+		t.Error("Expected preview to be loaded")
+	}
+	if view := model.View(); !strings.Contains(view, "Staged changes") {
+		t.Errorf("Expected preview pane in view, got %q", view)
+	}
+
+	// Closing and reopening does not reload the diff
+	newModel, _ = model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	model = newModel.(Model)
+	if model.showPreview {
+		t.Error("Expected preview to be hidden after esc")
+	}
+	if model.step != StepMessage {
+		t.Errorf("Expected to stay on StepMessage, got %d", model.step)
+	}
+
+	newModel, cmd = model.Update(ctrlP)
+	if cmd != nil {
+		t.Error("Expected no reload of an already loaded diff")
+	}
+	if !newModel.(Model).showPreview {
+		t.Error("Expected preview to be shown again")
+	}
+}
+
+func TestLoadStagedDiff(t *testing.T) {
+	setupRepo(t, repoOptions{files: map[string]string{"a.txt": "hello\n"}})
+
+	msg, ok := loadStagedDiff().(stagedDiffMsg)
+	if !ok {
+		t.Fatal("Expected stagedDiffMsg")
+	}
+	if msg.err != nil {
+		t.Fatalf("Unexpected error: %v", msg.err)
+	}
+
+	content := renderStagedDiff(msg.diff)
+	if !strings.Contains(content, "a.txt") || !strings.Contains(content, "+hello") {
+		t.Errorf("Expected rendered diff to contain the file and its content, got %q", content)
+	}
+}
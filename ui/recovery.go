@@ -0,0 +1,203 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/denysvitali/git-cc/pkg/git"
+)
+
+// editorDoneMsg is sent when the editor opened from the error step exits.
+type editorDoneMsg struct {
+	err error
+}
+
+// snapshotStagedFiles records the working tree content of the staged files,
+// so that files modified by hooks can be found after a failure.
+func (m Model) snapshotStagedFiles() Model {
+	m.commitHashes = nil
+	files, err := git.GetStagedFilesIn(m.commitDir())
+	if err != nil {
+		return m
+	}
+	m.commitHashes, _ = git.HashWorkTreeFiles(m.commitDir(), files)
+	return m
+}
+
+// errorType returns the type of the failed commit shown by the error step.
+func (m Model) errorType() git.ErrorType {
+	if m.gitResult == nil || m.gitResult.Success {
+		return git.ErrorTypeUnknown
+	}
+	return m.gitResult.Type
+}
+
+// isHookFailure reports whether the error step shows a failed hook.
+func (m Model) isHookFailure() bool {
+	errType := m.errorType()
+	return errType == git.ErrorTypeHookFailed || errType == git.ErrorTypeCommitMsgHookFailed
+}
+
+// hookStatus describes how the failed hook exited.
+func (m Model) hookStatus() string {
+	if !m.isHookFailure() || m.gitResult.Error == nil || m.gitResult.Error.Hook == "" {
+		return ""
+	}
+	err := m.gitResult.Error
+	return fmt.Sprintf("%s exited with status %d after %s",
+		err.Hook, err.HookExitCode, err.HookDuration.Round(time.Millisecond))
+}
+
+func (m Model) updateRecovery(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	errType := m.errorType()
+
+	switch msg.String() {
+	case "a":
+		if errType == git.ErrorTypeHookFailed {
+			return m.restageAndRetry()
+		}
+
+	case "n":
+		if m.isHookFailure() && m.opts.AllowNoVerify {
+			m.showError = false
+			return m.startCommit("--no-verify")
+		}
+
+	case "e":
+		if errType == git.ErrorTypeHookFailed {
+			return m.openFailingFiles()
+		}
+
+	case "i":
+		if errType == git.ErrorTypeIdentityMissing {
+			return m.enterIdentity()
+		}
+
+	case "u":
+		if errType == git.ErrorTypeIndexLocked && m.indexLockStale() {
+			return m.removeLockAndRetry()
+		}
+
+	case "d":
+		if errType == git.ErrorTypeDetachedHead {
+			m.allowDetached = true
+			m.showError = false
+			m.recoveryNote = ""
+			return m.startCommit()
+		}
+	}
+
+	return m, nil
+}
+
+// indexLockStale reports whether the index lock is old enough to be removed
+// safely.
+func (m Model) indexLockStale() bool {
+	stale, err := git.IsIndexLockStale()
+	return err == nil && stale
+}
+
+// removeLockAndRetry removes the index lock left behind by a git process
+// that died and commits again.
+func (m Model) removeLockAndRetry() (tea.Model, tea.Cmd) {
+	if err := git.RemoveIndexLock(); err != nil {
+		m.recoveryNote = err.Error()
+		return m, nil
+	}
+	m.showError = false
+	m.recoveryNote = ""
+	return m.startCommit()
+}
+
+// restageAndRetry stages the changes the hook made, such as those of a
+// formatter, and commits again with the same message. Only the changes of the
+// hook are staged, so hunks left unstaged on purpose stay unstaged.
+func (m Model) restageAndRetry() (tea.Model, tea.Cmd) {
+	changed, err := git.ChangedFiles(m.commitOpts.Dir, m.commitHashes)
+	if err != nil {
+		m.recoveryNote = err.Error()
+		return m, nil
+	}
+	if len(changed) == 0 {
+		m.recoveryNote = "The hook did not modify any staged file."
+		return m, nil
+	}
+
+	if err := git.StageWorkTreeChanges(m.commitOpts.Dir, m.commitHashes, changed); err != nil {
+		m.recoveryNote = fmt.Sprintf("Could not stage the changes of the hook alone, stage them yourself: %v", err)
+		return m, nil
+	}
+
+	m.showError = false
+	m.recoveryNote = ""
+	m = m.invalidatePreview()
+	return m.startCommit()
+}
+
+// openFailingFiles opens the staged files mentioned in the hook output in
+// the editor, or every staged file if none is mentioned.
+func (m Model) openFailingFiles() (tea.Model, tea.Cmd) {
+	staged, err := git.GetStagedFilesIn(m.commitOpts.Dir)
+	if err != nil {
+		m.recoveryNote = err.Error()
+		return m, nil
+	}
+
+	files := failingFiles(staged, m.gitResult.Details)
+	if len(files) == 0 {
+		m.recoveryNote = "No files to open."
+		return m, nil
+	}
+
+	cmd, err := git.EditorCommand(m.commitOpts.Dir, files)
+	if err != nil {
+		m.recoveryNote = err.Error()
+		return m, nil
+	}
+
+	return m, tea.ExecProcess(cmd, func(err error) tea.Msg {
+		return editorDoneMsg{err: err}
+	})
+}
+
+// failingFiles returns the staged files mentioned in the hook output, or all
+// staged files if none is mentioned.
+func failingFiles(staged []string, output string) []string {
+	var files []string
+	for _, file := range staged {
+		if strings.Contains(output, file) {
+			files = append(files, file)
+		}
+	}
+	if len(files) == 0 {
+		return staged
+	}
+	return files
+}
+
+// recoveryHelp lists the actions offered by the error step.
+func (m Model) recoveryHelp() string {
+	actions := []string{"r: edit message"}
+	switch m.errorType() {
+	case git.ErrorTypeHookFailed:
+		actions = append(actions, "a: re-stage hook changes and retry")
+	case git.ErrorTypeIdentityMissing:
+		actions = append(actions, "i: set identity for this repository")
+	case git.ErrorTypeIndexLocked:
+		if m.indexLockStale() {
+			actions = append(actions, "u: remove the stale lock and retry")
+		}
+	case git.ErrorTypeDetachedHead:
+		actions = append(actions, "d: commit on the detached HEAD anyway")
+	}
+	if m.isHookFailure() && m.opts.AllowNoVerify {
+		actions = append(actions, "n: retry without hooks")
+	}
+	if m.errorType() == git.ErrorTypeHookFailed {
+		actions = append(actions, "e: open files in editor")
+	}
+	return strings.Join(append(actions, "Ctrl+C: quit"), " • ")
+}
@@ -0,0 +1,125 @@
+package ui
+
+import (
+	"os"
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/denysvitali/git-cc/pkg/git"
+)
+
+// formatterHook rewrites staged files and fails, like a formatter that
+// refuses the commit after fixing the files.
+const formatterHook = `#!/bin/sh
+if grep -q unformatted a.txt; then
+  echo formatted > a.txt
+  echo "a.txt was reformatted"
+  exit 1
+fi
+`
+
+func TestRestageAndRetry(t *testing.T) {
+	setupRepo(t, repoOptions{hook: formatterHook})
+	if err := os.WriteFile("a.txt", []byte("unformatted\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := git.StageFiles([]string{"a.txt"}); err != nil {
+		t.Fatalf("Failed to stage file: %v", err)
+	}
+
+	model := InitialModel()
+	model.step = StepMessage
+	model.message.SetValue("add formatted file")
+	newModel, _ := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model = finishCommit(t, newModel.(Model))
+
+	if model.step != StepError || !model.isHookFailure() {
+		t.Fatalf("Expected a hook failure, got step %d and result %+v", model.step, model.gitResult)
+	}
+	if view := model.View(); !strings.Contains(view, "re-stage") {
+		t.Errorf("Expected recovery options in view, got %q", view)
+	}
+
+	// 'n' is not offered unless allowed by config
+	newModel, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}})
+	if cmd != nil || newModel.(Model).step != StepError {
+		t.Error("Expected --no-verify retry to be disabled")
+	}
+
+	newModel, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'a'}})
+	model = finishCommit(t, newModel.(Model))
+	if model.gitResult == nil || !model.gitResult.Success {
+		t.Fatalf("Expected retry to succeed, got %+v", model.gitResult)
+	}
+	if model.commitMsg != "feat: add formatted file" {
+		t.Errorf("Expected message to be kept, got %q", model.commitMsg)
+	}
+}
+
+func TestRetryWithoutHooks(t *testing.T) {
+	setupRepo(t, repoOptions{hook: "#!/bin/sh\necho lint failed\nexit 1\n"})
+
+	model := NewModel(Options{AllowNoVerify: true})
+	model.step = StepMessage
+	model.message.SetValue("skip hooks")
+	newModel, _ := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model = finishCommit(t, newModel.(Model))
+	if !model.isHookFailure() {
+		t.Fatalf("Expected a hook failure, got %+v", model.gitResult)
+	}
+
+	newModel, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}})
+	model = newModel.(Model)
+	if model.step != StepCommitting {
+		t.Fatalf("Expected StepCommitting (%d), got %d", StepCommitting, model.step)
+	}
+	model = finishCommit(t, model)
+	if !model.gitResult.Success {
+		t.Errorf("Expected commit without hooks to succeed, got %+v", model.gitResult)
+	}
+}
+
+func TestFailingFiles(t *testing.T) {
+	staged := []string{"a.go", "b.go", "c.go"}
+
+	files := failingFiles(staged, "b.go:3: missing return\nc.go:1: bad import")
+	if !reflect.DeepEqual(files, []string{"b.go", "c.go"}) {
+		t.Errorf("Expected mentioned files, got %v", files)
+	}
+
+	files = failingFiles(staged, "something went wrong")
+	if !reflect.DeepEqual(files, staged) {
+		t.Errorf("Expected all staged files, got %v", files)
+	}
+}
+
+func TestCommitOnDetachedHead(t *testing.T) {
+	setupRepo(t, repoOptions{})
+	if err := os.WriteFile("b.txt", []byte("b"), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	for _, args := range [][]string{{"commit", "-q", "-m", "initial"}, {"checkout", "-q", "--detach"}, {"add", "b.txt"}} {
+		if err := exec.Command("git", args...).Run(); err != nil {
+			t.Fatalf("Failed to run git %v: %v", args, err)
+		}
+	}
+
+	model := NewModel(Options{BlockDetachedHead: true})
+	model.step = StepMessage
+	model.message.SetValue("detached")
+	newModel, _ := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model = finishCommit(t, newModel.(Model))
+	if model.step != StepError || model.gitResult.Type != git.ErrorTypeDetachedHead {
+		t.Fatalf("Expected a detached HEAD error, got %+v", model.gitResult)
+	}
+
+	newModel, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'d'}})
+	model = finishCommit(t, newModel.(Model))
+	if !model.gitResult.Success {
+		t.Errorf("Expected commit on detached HEAD to succeed, got %+v", model.gitResult)
+	}
+}
@@ -0,0 +1,288 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/denysvitali/git-cc/pkg/git"
+)
+
+// maxBuckets is the number of buckets that can be assigned with the digit keys.
+const maxBuckets = 9
+
+// splitBucket is a group of staged files committed together.
+type splitBucket struct {
+	files []string
+	// scope is the suggested scope for the commit.
+	scope string
+	// header is the header of the commit once it succeeded.
+	header string
+	// result describes why the index could not be prepared for the commit.
+	result *git.CommitResult
+}
+
+// splitSession tracks the commits of a split in progress.
+type splitSession struct {
+	// tree is the index as it was when the split started.
+	tree    string
+	buckets []splitBucket
+	current int
+}
+
+// remainingFiles returns the files of the buckets that are not committed yet.
+func (s *splitSession) remainingFiles() []string {
+	var files []string
+	for _, bucket := range s.buckets[s.current:] {
+		files = append(files, bucket.files...)
+	}
+	return files
+}
+
+// suggestScope suggests a commit scope for a file from its directory, such as
+// "git" for pkg/git/git.go. Files at the repository root have no scope.
+func suggestScope(file string) string {
+	dir := path.Dir(file)
+	if dir == "." {
+		return ""
+	}
+	return path.Base(dir)
+}
+
+// enterBuckets switches to the step assigning staged files to buckets.
+func (m Model) enterBuckets() Model {
+	m.bucketErr = nil
+	files, err := git.GetStagedFiles()
+	if err != nil {
+		m.stagingErr = err
+		return m
+	}
+	if len(files) < 2 {
+		return m
+	}
+
+	m.step = StepBuckets
+	m.bucketFiles = files
+	m.bucketOf = make([]int, len(files))
+	for i := range m.bucketOf {
+		m.bucketOf[i] = 1
+	}
+	m.bucketCursor = 0
+	return m
+}
+
+func (m Model) updateBuckets(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch k := msg.String(); k {
+	case "up", "k":
+		if m.bucketCursor > 0 {
+			m.bucketCursor--
+		}
+
+	case "down", "j":
+		if m.bucketCursor < len(m.bucketFiles)-1 {
+			m.bucketCursor++
+		}
+
+	case "1", "2", "3", "4", "5", "6", "7", "8", "9":
+		m.bucketOf[m.bucketCursor] = int(k[0] - '0')
+		if m.bucketCursor < len(m.bucketFiles)-1 {
+			m.bucketCursor++
+		}
+
+	case "g":
+		m.bucketOf = groupByScope(m.bucketFiles)
+
+	case "esc":
+		m.step = StepTypeSelect
+
+	case "enter":
+		return m.startSplit()
+	}
+
+	return m, nil
+}
+
+// groupByScope assigns files sharing a suggested scope to the same bucket.
+func groupByScope(files []string) []int {
+	buckets := make(map[string]int)
+	bucketOf := make([]int, len(files))
+	for i, file := range files {
+		scope := suggestScope(file)
+		bucket, ok := buckets[scope]
+		if !ok {
+			bucket = min(len(buckets)+1, maxBuckets)
+			buckets[scope] = bucket
+		}
+		bucketOf[i] = bucket
+	}
+	return bucketOf
+}
+
+// startSplit saves the index and starts the flow for the first bucket.
+func (m Model) startSplit() (tea.Model, tea.Cmd) {
+	var buckets []splitBucket
+	for n := 1; n <= maxBuckets; n++ {
+		var bucket splitBucket
+		for i, file := range m.bucketFiles {
+			if m.bucketOf[i] == n {
+				bucket.files = append(bucket.files, file)
+			}
+		}
+		if len(bucket.files) == 0 {
+			continue
+		}
+		bucket.scope = commonScope(bucket.files)
+		buckets = append(buckets, bucket)
+	}
+
+	if len(buckets) < 2 {
+		m.bucketErr = errors.New("assign the files to at least two groups")
+		return m, nil
+	}
+
+	tree, err := git.WriteTree()
+	if err != nil {
+		m.bucketErr = err
+		return m, nil
+	}
+
+	m.split = &splitSession{tree: tree, buckets: buckets}
+	return m.startBucket()
+}
+
+// commonScope returns the suggested scope shared by all files, if any.
+func commonScope(files []string) string {
+	scope := suggestScope(files[0])
+	for _, file := range files[1:] {
+		if suggestScope(file) != scope {
+			return ""
+		}
+	}
+	return scope
+}
+
+// startBucket leaves only the files of the current bucket staged and starts
+// the type/scope/message flow for it.
+func (m Model) startBucket() (tea.Model, tea.Cmd) {
+	bucket := m.split.buckets[m.split.current]
+	remaining := m.split.remainingFiles()
+
+	others := remaining[len(bucket.files):]
+
+	if err := git.RestoreIndex(m.split.tree, remaining); err != nil {
+		return m.failSplit(err)
+	}
+	if err := git.UnstageFiles(others); err != nil {
+		return m.failSplit(err)
+	}
+
+	m = m.invalidatePreview()
+	m.list.Title = fmt.Sprintf("Commit %d/%d: select the type of change", m.split.current+1, len(m.split.buckets))
+	m.scope.SetValue(bucket.scope)
+	m.message.SetValue("")
+	m.body.SetValue("")
+	m.step = StepTypeSelect
+	return m, nil
+}
+
+// finishSplitCommit records the result of the commit of the current bucket and
+// moves on to the next one, or to the summary when done. A failed commit goes
+// to the error step with only the files of the bucket staged, so that it can
+// be retried before the split resumes.
+func (m Model) finishSplitCommit() (tea.Model, tea.Cmd) {
+	if !m.gitResult.Success {
+		m.step = StepError
+		m.showError = true
+		return m, nil
+	}
+
+	bucket := &m.split.buckets[m.split.current]
+	bucket.header, _, _ = strings.Cut(m.commitMsg, "\n")
+	m.split.current++
+	if m.split.current < len(m.split.buckets) {
+		return m.startBucket()
+	}
+
+	m.list.Title = typeSelectTitle
+	m.step = StepSplitSummary
+	return m, nil
+}
+
+// failSplit aborts the split after an error while preparing the index.
+func (m Model) failSplit(err error) (tea.Model, tea.Cmd) {
+	m.split.buckets[m.split.current].result = &git.CommitResult{
+		Success: false,
+		Message: "Failed to prepare the index",
+		Details: err.Error(),
+	}
+	m.restoreSplit()
+	m.step = StepSplitSummary
+	return m, nil
+}
+
+// restoreSplit stages again every file that has not been committed yet.
+func (m Model) restoreSplit() {
+	if m.split == nil || m.split.current >= len(m.split.buckets) {
+		return
+	}
+	// Nothing else can be done if this fails; the files stay in the work tree
+	_ = git.RestoreIndex(m.split.tree, m.split.remainingFiles())
+}
+
+// splitHeader describes the commit being prepared during a split.
+func (m Model) splitHeader() string {
+	if m.split == nil || m.split.current >= len(m.split.buckets) {
+		return ""
+	}
+	bucket := m.split.buckets[m.split.current]
+	return fmt.Sprintf("Commit %d/%d: %s\n\n", m.split.current+1, len(m.split.buckets), strings.Join(bucket.files, ", "))
+}
+
+func (m Model) bucketsView() string {
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("Assign staged files to commits:") + "\n\n")
+
+	start, end := visibleRange(m.bucketCursor, len(m.bucketFiles), m.height-stagingChromeHeight)
+	for i := start; i < end; i++ {
+		cursor := "  "
+		style := listItemStyle
+		if i == m.bucketCursor {
+			cursor = "❯ "
+			style = selectedItemStyle
+		}
+		b.WriteString(style.Render(fmt.Sprintf("%s[%d] %s", cursor, m.bucketOf[i], m.bucketFiles[i])) + "\n")
+	}
+
+	if m.bucketErr != nil {
+		b.WriteString("\n" + errorStyle.Render(m.bucketErr.Error()) + "\n")
+	}
+
+	b.WriteString("\n" + promptStyle.Render("1-9: assign group • g: group by scope • enter: start • esc: back"))
+	return b.String()
+}
+
+func (m Model) splitSummaryView() string {
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("Split commit summary") + "\n\n")
+
+	for i, bucket := range m.split.buckets {
+		files := fmt.Sprintf("(%d files)", len(bucket.files))
+		switch {
+		case bucket.header != "":
+			b.WriteString(fmt.Sprintf("✓ %s %s\n", bucket.header, files))
+		case bucket.result != nil:
+			b.WriteString(errorStyle.Render(fmt.Sprintf("✗ commit %d failed: %s %s", i+1, bucket.result.Message, files)) + "\n")
+			if bucket.result.Details != "" && bucket.result.Details != bucket.result.Message {
+				b.WriteString(bucket.result.Details + "\n")
+			}
+		default:
+			b.WriteString(fmt.Sprintf("- commit %d not created, files left staged %s\n", i+1, files))
+		}
+	}
+
+	b.WriteString("\n" + promptStyle.Render("Press Enter to exit"))
+	return b.String()
+}
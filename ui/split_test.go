@@ -0,0 +1,153 @@
+package ui
+
+import (
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/denysvitali/git-cc/pkg/git"
+)
+
+func TestSuggestScope(t *testing.T) {
+	tests := map[string]string{
+		"README.md":         "",
+		"ui/model.go":       "ui",
+		"pkg/git/status.go": "git",
+	}
+	for file, expected := range tests {
+		if scope := suggestScope(file); scope != expected {
+			t.Errorf("suggestScope(%q) = %q, expected %q", file, scope, expected)
+		}
+	}
+}
+
+func TestGroupByScope(t *testing.T) {
+	files := []string{"README.md", "ui/model.go", "pkg/git/git.go", "ui/split.go", "main.go"}
+	expected := []int{1, 2, 3, 2, 1}
+	if result := groupByScope(files); !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
+// splitFiles are the staged files of the split tests, in two directories.
+var splitFiles = map[string]string{"main.go": "main.go", "docs/guide.md": "docs/guide.md"}
+
+func commitSplitBucket(t *testing.T, model Model, message string) Model {
+	t.Helper()
+
+	// Type selection, scope, then message
+	newModel, _ := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	newModel, _ = newModel.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model = newModel.(Model)
+	model.message.SetValue(message)
+	newModel, _ = model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	return finishCommit(t, newModel.(Model))
+}
+
+func TestSplitCommits(t *testing.T) {
+	setupRepo(t, repoOptions{files: splitFiles})
+
+	model := InitialModel()
+	newModel, _ := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'m'}})
+	model = newModel.(Model)
+	if model.step != StepBuckets {
+		t.Fatalf("Expected StepBuckets (%d), got %d", StepBuckets, model.step)
+	}
+
+	newModel, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'g'}})
+	newModel, _ = newModel.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model = newModel.(Model)
+	if model.split == nil || len(model.split.buckets) != 2 {
+		t.Fatal("Expected a split session with 2 buckets")
+	}
+
+	staged, _ := git.GetStagedFiles()
+	if len(staged) != 1 || staged[0] != "docs/guide.md" {
+		t.Errorf("Expected only docs/guide.md staged for the first commit, got %v", staged)
+	}
+	if model.scope.Value() != "docs" {
+		t.Errorf("Expected suggested scope 'docs', got %q", model.scope.Value())
+	}
+
+	model = commitSplitBucket(t, model, "add guide")
+	staged, _ = git.GetStagedFiles()
+	if len(staged) != 1 || staged[0] != "main.go" {
+		t.Errorf("Expected main.go staged for the second commit, got %v", staged)
+	}
+
+	model = commitSplitBucket(t, model, "add main")
+	if model.step != StepSplitSummary {
+		t.Fatalf("Expected StepSplitSummary (%d), got %d", StepSplitSummary, model.step)
+	}
+
+	log, _ := exec.Command("git", "log", "--format=%s").Output()
+	if string(log) != "feat: add main\nfeat(docs): add guide\n" {
+		t.Errorf("Unexpected commit log %q", string(log))
+	}
+	if view := model.View(); !strings.Contains(view, "feat(docs): add guide") {
+		t.Errorf("Expected summary to list the commits, got %q", view)
+	}
+}
+
+func TestSplitCommitsFailure(t *testing.T) {
+	setupRepo(t, repoOptions{hook: "#!/bin/sh\nexit 1\n", files: splitFiles})
+
+	model := InitialModel().enterBuckets()
+	model.bucketOf = groupByScope(model.bucketFiles)
+	newModel, _ := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model = commitSplitBucket(t, newModel.(Model), "add guide")
+
+	if model.step != StepError || !model.isHookFailure() {
+		t.Fatalf("Expected a hook failure, got step %d and result %+v", model.step, model.gitResult)
+	}
+	if view := model.View(); !strings.Contains(view, "Commit 1/2") {
+		t.Errorf("Expected the error to name the commit of the split, got %q", view)
+	}
+	staged, _ := git.GetStagedFiles()
+	if len(staged) != 1 || staged[0] != "docs/guide.md" {
+		t.Errorf("Expected the files of the failed commit to stay staged alone, got %v", staged)
+	}
+
+	// Once the hook passes, the retry resumes the split
+	if err := writeHook("#!/bin/sh\n"); err != nil {
+		t.Fatalf("Failed to update hook: %v", err)
+	}
+	newModel, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'r'}})
+	newModel, _ = newModel.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model = finishCommit(t, newModel.(Model))
+	staged, _ = git.GetStagedFiles()
+	if model.step != StepTypeSelect || len(staged) != 1 || staged[0] != "main.go" {
+		t.Fatalf("Expected the second commit with main.go staged, got step %d and %v", model.step, staged)
+	}
+
+	model = commitSplitBucket(t, model, "add main")
+	if model.step != StepSplitSummary {
+		t.Fatalf("Expected StepSplitSummary (%d), got %d", StepSplitSummary, model.step)
+	}
+	log, _ := exec.Command("git", "log", "--format=%s").Output()
+	if string(log) != "feat: add main\nfeat(docs): add guide\n" {
+		t.Errorf("Unexpected commit log %q", string(log))
+	}
+}
+
+func TestSplitCommitsFailureQuit(t *testing.T) {
+	setupRepo(t, repoOptions{hook: "#!/bin/sh\nexit 1\n", files: splitFiles})
+
+	model := InitialModel().enterBuckets()
+	model.bucketOf = groupByScope(model.bucketFiles)
+	newModel, _ := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model = commitSplitBucket(t, newModel.(Model), "add guide")
+	if model.step != StepError {
+		t.Fatalf("Expected StepError (%d), got %d", StepError, model.step)
+	}
+
+	// Every file is staged again when quitting after the failure
+	model.Update(tea.KeyMsg{Type: tea.KeyCtrlC})
+	staged, _ := git.GetStagedFiles()
+	if len(staged) != 2 {
+		t.Errorf("Expected both files to be staged again, got %v", staged)
+	}
+}
@@ -0,0 +1,184 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/denysvitali/git-cc/pkg/git"
+)
+
+// stagingChromeHeight is the number of lines of the staging step that are
+// not file rows (title, help and padding).
+const stagingChromeHeight = 8
+
+// stagingEntry is a row of the staging step.
+type stagingEntry struct {
+	file     git.FileStatus
+	selected bool
+}
+
+// enterStaging switches to the staging step and loads the working tree status.
+func (m Model) enterStaging() Model {
+	m.step = StepStage
+	m.stagingCursor = 0
+	m.stagingFiles = nil
+	m.stagingErr = nil
+
+	files, err := git.GetWorkingTreeStatus()
+	if err != nil {
+		m.stagingErr = err
+		return m
+	}
+
+	for _, file := range files {
+		m.stagingFiles = append(m.stagingFiles, stagingEntry{file: file, selected: file.IsStaged()})
+	}
+	return m
+}
+
+func (m Model) updateStaging(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "up", "k":
+		if m.stagingCursor > 0 {
+			m.stagingCursor--
+		}
+
+	case "down", "j":
+		if m.stagingCursor < len(m.stagingFiles)-1 {
+			m.stagingCursor++
+		}
+
+	case " ":
+		if m.stagingCursor < len(m.stagingFiles) {
+			m.stagingFiles[m.stagingCursor].selected = !m.stagingFiles[m.stagingCursor].selected
+		}
+
+	case "a":
+		allSelected := true
+		for _, entry := range m.stagingFiles {
+			allSelected = allSelected && entry.selected
+		}
+		for i := range m.stagingFiles {
+			m.stagingFiles[i].selected = !allSelected
+		}
+
+	case "p":
+		if m.stagingCursor < len(m.stagingFiles) {
+			file := m.stagingFiles[m.stagingCursor].file
+			if file.IsUntracked() || !file.HasUnstagedChanges() {
+				m.stagingErr = fmt.Errorf("%s has no unstaged hunks", file.Path)
+				return m, nil
+			}
+			return m.enterHunks(file.Path), nil
+		}
+
+	case "esc":
+		// Going back is only possible when there is something to commit
+		if files, err := git.GetStagedFiles(); err == nil && len(files) > 0 {
+			m.step = StepTypeSelect
+		}
+
+	case "enter":
+		return m.applyStaging()
+	}
+
+	return m, nil
+}
+
+// applyStaging stages the selected files, unstages the deselected ones and
+// continues to type selection once something is staged.
+func (m Model) applyStaging() (tea.Model, tea.Cmd) {
+	var stage, unstage []string
+	for _, entry := range m.stagingFiles {
+		staged := entry.file.IsStaged()
+		switch {
+		case entry.selected && !staged:
+			stage = append(stage, entry.file.Path)
+		case !entry.selected && staged:
+			unstage = append(unstage, entry.file.Path)
+			if entry.file.OrigPath != "" {
+				unstage = append(unstage, entry.file.OrigPath)
+			}
+		}
+	}
+
+	m = m.invalidatePreview()
+	if err := git.StageFiles(stage); err != nil {
+		m.stagingErr = err
+		return m, nil
+	}
+	if err := git.UnstageFiles(unstage); err != nil {
+		m.stagingErr = err
+		return m, nil
+	}
+
+	files, err := git.GetStagedFiles()
+	if err != nil {
+		m.stagingErr = err
+		return m, nil
+	}
+	if len(files) == 0 {
+		m = m.enterStaging()
+		m.stagingErr = errors.New("select at least one file to stage")
+		return m, nil
+	}
+
+	m.step = StepTypeSelect
+	return m, nil
+}
+
+func (m Model) stagingView() string {
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("Select files to stage:") + "\n\n")
+
+	if len(m.stagingFiles) == 0 && m.stagingErr == nil {
+		b.WriteString("No changes in the working tree.\n")
+	}
+
+	start, end := visibleRange(m.stagingCursor, len(m.stagingFiles), m.height-stagingChromeHeight)
+	for i := start; i < end; i++ {
+		entry := m.stagingFiles[i]
+
+		cursor := "  "
+		style := listItemStyle
+		if i == m.stagingCursor {
+			cursor = "❯ "
+			style = selectedItemStyle
+		}
+
+		check := "[ ]"
+		if entry.selected {
+			check = "[x]"
+		}
+
+		path := entry.file.Path
+		if entry.file.OrigPath != "" {
+			path = entry.file.OrigPath + " -> " + path
+		}
+
+		b.WriteString(style.Render(fmt.Sprintf("%s%s %s %s", cursor, check, entry.file.Code(), path)) + "\n")
+	}
+
+	if m.stagingErr != nil {
+		b.WriteString("\n" + errorStyle.Render(m.stagingErr.Error()) + "\n")
+	}
+
+	b.WriteString("\n" + promptStyle.Render("space: toggle • a: toggle all • p: stage hunks • enter: confirm • esc: back"))
+	return b.String()
+}
+
+// visibleRange returns the window of rows to render so that the cursor stays
+// visible within the given height.
+func visibleRange(cursor, total, height int) (start, end int) {
+	if height <= 0 || total <= height {
+		return 0, total
+	}
+	start = cursor - height + 1
+	if start < 0 {
+		start = 0
+	}
+	return start, start + height
+}
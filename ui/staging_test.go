@@ -0,0 +1,89 @@
+package ui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/denysvitali/git-cc/pkg/git"
+)
+
+func TestStagingStep(t *testing.T) {
+	setupRepo(t, repoOptions{files: map[string]string{"a.txt": "a.txt", "b.txt": "b.txt"}})
+	runGit(t, "reset", "-q")
+
+	// A dry run leaves the index alone
+	if model := NewModel(Options{Stage: true, DryRun: true}); model.step == StepStage {
+		t.Error("Expected no staging step in a dry run")
+	}
+
+	model := NewModel(Options{Stage: true})
+	if model.step != StepStage {
+		t.Fatalf("Expected StepStage (%d), got %d", StepStage, model.step)
+	}
+	if len(model.stagingFiles) != 2 {
+		t.Fatalf("Expected 2 files, got %d", len(model.stagingFiles))
+	}
+
+	// Confirming without a selection stays on the staging step
+	newModel, _ := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model = newModel.(Model)
+	if model.step != StepStage || model.stagingErr == nil {
+		t.Error("Expected to stay on StepStage with an error when nothing is selected")
+	}
+
+	// Select the second file and confirm
+	newModel, _ = model.Update(tea.KeyMsg{Type: tea.KeyDown})
+	newModel, _ = newModel.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+	newModel, _ = newModel.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model = newModel.(Model)
+
+	if model.step != StepTypeSelect {
+		t.Errorf("Expected StepTypeSelect (%d), got %d", StepTypeSelect, model.step)
+	}
+	staged, _ := git.GetStagedFiles()
+	if len(staged) != 1 || staged[0] != "b.txt" {
+		t.Errorf("Expected b.txt to be staged, got %v", staged)
+	}
+
+	// Reopening the step shows the staged file selected, deselecting unstages it
+	newModel, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'s'}})
+	model = newModel.(Model)
+	if model.step != StepStage {
+		t.Fatalf("Expected StepStage (%d), got %d", StepStage, model.step)
+	}
+	for i, entry := range model.stagingFiles {
+		model.stagingFiles[i].selected = entry.file.Path == "a.txt"
+	}
+	newModel, _ = model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model = newModel.(Model)
+
+	staged, _ = git.GetStagedFiles()
+	if len(staged) != 1 || staged[0] != "a.txt" {
+		t.Errorf("Expected only a.txt to be staged, got %v", staged)
+	}
+
+	if view := model.enterStaging().View(); view == "" {
+		t.Error("Expected non-empty view for StepStage")
+	}
+}
+
+func TestVisibleRange(t *testing.T) {
+	tests := []struct {
+		cursor, total, height int
+		start, end            int
+	}{
+		{cursor: 0, total: 3, height: 10, start: 0, end: 3},
+		{cursor: 0, total: 30, height: 10, start: 0, end: 10},
+		{cursor: 15, total: 30, height: 10, start: 6, end: 16},
+		{cursor: 5, total: 30, height: 0, start: 0, end: 30},
+	}
+
+	for _, tt := range tests {
+		start, end := visibleRange(tt.cursor, tt.total, tt.height)
+		if start != tt.start || end != tt.end {
+			t.Errorf("visibleRange(%d, %d, %d) = %d, %d; expected %d, %d",
+				tt.cursor, tt.total, tt.height, start, end, tt.start, tt.end)
+		}
+	}
+}
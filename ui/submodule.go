@@ -0,0 +1,179 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/denysvitali/git-cc/pkg/draft"
+	"github.com/denysvitali/git-cc/pkg/git"
+)
+
+// submoduleSession tracks the commits made inside submodules before the
+// commit that bumps them in the repository.
+type submoduleSession struct {
+	submodules []git.Submodule
+	// pending are the indexes of the submodules with staged changes of their
+	// own, committed in order.
+	pending []int
+	current int
+	err     error
+}
+
+// active reports whether a submodule commit is being prepared.
+func (s *submoduleSession) active() bool {
+	return s != nil && s.current < len(s.pending)
+}
+
+// path returns the path of the submodule being committed.
+func (s *submoduleSession) path() string {
+	return s.submodules[s.pending[s.current]].Path
+}
+
+// offerSubmodules switches to the submodule prompt if any submodule has
+// changes to commit.
+func (m Model) offerSubmodules() Model {
+	if !m.opts.Submodules {
+		return m
+	}
+	submodules, err := git.GetSubmodules()
+	if err != nil || len(submodules) == 0 {
+		return m
+	}
+
+	session := &submoduleSession{submodules: submodules}
+	for i, submodule := range submodules {
+		if len(submodule.Staged) > 0 {
+			session.pending = append(session.pending, i)
+		}
+	}
+	m.submodules = session
+	m.step = StepSubmodules
+	return m
+}
+
+func (m Model) updateSubmodules(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "y", "enter":
+		if m.submodules.active() {
+			return m.startSubmodule(), nil
+		}
+		return m.proposeBump()
+
+	case "n", "esc":
+		m.submodules = nil
+		return m.continueStart(), nil
+	}
+	return m, nil
+}
+
+// startSubmodule prepares the commit inside the current submodule.
+func (m Model) startSubmodule() Model {
+	m.list.Title = fmt.Sprintf("Submodule %s: select the type of change", m.submodules.path())
+	m.scope.SetValue("")
+	m.message.SetValue("")
+	m.body.SetValue("")
+	m.step = StepTypeSelect
+	return m
+}
+
+// finishSubmoduleCommit moves on to the next submodule after a commit, or to
+// the bump of the submodules in the repository after the last one.
+func (m Model) finishSubmoduleCommit() (tea.Model, tea.Cmd) {
+	if !m.gitResult.Success {
+		m.step = StepError
+		m.showError = true
+		return m, nil
+	}
+
+	m.submodules.current++
+	if m.submodules.active() {
+		return m.startSubmodule(), nil
+	}
+	return m.proposeBump()
+}
+
+// proposeBump stages the new submodule commits in the repository and fills in
+// a message listing them.
+func (m Model) proposeBump() (tea.Model, tea.Cmd) {
+	session := m.submodules
+	m.list.Title = typeSelectTitle
+
+	paths := make([]string, len(session.submodules))
+	for i, submodule := range session.submodules {
+		paths[i] = submodule.Path
+	}
+	if err := git.StageFiles(paths); err != nil {
+		session.err = err
+		m.step = StepSubmodules
+		return m, nil
+	}
+
+	logs := make(map[string][]string, len(paths))
+	for _, path := range paths {
+		headers, err := git.SubmoduleLog(path)
+		if err != nil {
+			session.err = err
+			m.step = StepSubmodules
+			return m, nil
+		}
+		logs[path] = headers
+	}
+
+	subject, body := bumpMessage(paths, logs)
+	m = m.applyDraft(&draft.Draft{Type: "chore", Scope: "deps", Subject: subject, Body: body})
+	m = m.invalidatePreview()
+	m.submodules = nil
+	m.step = StepMessage
+	m.message.Focus()
+	m.message.CursorEnd()
+	return m, nil
+}
+
+// bumpMessage returns the subject and body of the commit bumping the given
+// submodules, listing the headers of their new commits.
+func bumpMessage(paths []string, logs map[string][]string) (subject, body string) {
+	sections := make([]string, 0, len(paths))
+	for _, path := range paths {
+		lines := []string{path + ":"}
+		for _, header := range logs[path] {
+			lines = append(lines, "- "+header)
+		}
+		sections = append(sections, strings.Join(lines, "\n"))
+	}
+	return "bump " + strings.Join(paths, ", "), strings.Join(sections, "\n\n")
+}
+
+// submoduleHeader describes the submodule being committed.
+func (m Model) submoduleHeader() string {
+	if !m.submodules.active() {
+		return ""
+	}
+	return fmt.Sprintf("Submodule %s (%d/%d)\n\n", m.submodules.path(), m.submodules.current+1, len(m.submodules.pending))
+}
+
+func (m Model) submodulesView() string {
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("Submodules with changes") + "\n\n")
+
+	for _, submodule := range m.submodules.submodules {
+		switch {
+		case len(submodule.Staged) > 0:
+			b.WriteString(fmt.Sprintf("- %s: %d staged files to commit inside\n", submodule.Path, len(submodule.Staged)))
+		case submodule.PointerStaged:
+			b.WriteString(fmt.Sprintf("- %s: new commit staged\n", submodule.Path))
+		}
+	}
+
+	if m.submodules.err != nil {
+		b.WriteString("\n" + errorStyle.Render(m.submodules.err.Error()) + "\n")
+	}
+
+	help := "y: bump the submodules in a chore(deps) commit • n: commit normally"
+	if m.submodules.active() {
+		help = "y: commit inside each submodule first, then bump them • n: commit normally"
+	}
+	b.WriteString("\n" + promptStyle.Render(help))
+	return b.String()
+}
@@ -0,0 +1,140 @@
+package ui
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestBumpMessage(t *testing.T) {
+	subject, body := bumpMessage([]string{"lib", "vendor/ui"}, map[string][]string{
+		"lib":       {"feat: add a", "fix: b"},
+		"vendor/ui": {"docs: readme"},
+	})
+	if subject != "bump lib, vendor/ui" {
+		t.Errorf("Unexpected subject %q", subject)
+	}
+	expected := "lib:\n- feat: add a\n- fix: b\n\nvendor/ui:\n- docs: readme"
+	if body != expected {
+		t.Errorf("Expected body %q, got %q", expected, body)
+	}
+}
+
+// setupSubmoduleRepo creates a repository with a submodule at lib that has a
+// staged file of its own.
+func setupSubmoduleRepo(t *testing.T) {
+	t.Helper()
+
+	upstream := t.TempDir()
+	runGit(t, "init", "-q", upstream)
+	runGit(t, "-C", upstream, "-c", "user.name=Test User", "-c", "user.email=test@example.com",
+		"commit", "-q", "--allow-empty", "-m", "initial")
+
+	setupRepo(t, repoOptions{})
+	runGit(t, "-c", "protocol.file.allow=always", "submodule", "add", "-q", upstream, "lib")
+	runGit(t, "commit", "-q", "-m", "add lib")
+	runGit(t, "-C", "lib", "config", "user.name", "Test User")
+	runGit(t, "-C", "lib", "config", "user.email", "test@example.com")
+
+	if err := os.WriteFile(filepath.Join("lib", "a.txt"), []byte("a"), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	runGit(t, "-C", "lib", "add", "a.txt")
+}
+
+func TestSubmoduleCommits(t *testing.T) {
+	setupSubmoduleRepo(t)
+
+	model := NewModel(Options{Submodules: true, Stage: true})
+	if model.step != StepSubmodules {
+		t.Fatalf("Expected StepSubmodules (%d), got %d", StepSubmodules, model.step)
+	}
+	if view := model.View(); !strings.Contains(view, "lib: 1 staged files") {
+		t.Errorf("Expected the prompt to list lib, got %q", view)
+	}
+
+	newModel, _ := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}})
+	model = newModel.(Model)
+	if model.step != StepTypeSelect || !strings.Contains(model.list.Title, "Submodule lib") {
+		t.Fatalf("Expected the type selection for lib, got step %d titled %q", model.step, model.list.Title)
+	}
+
+	model = commitSplitBucket(t, model, "add a")
+	if model.step != StepMessage {
+		t.Fatalf("Expected StepMessage (%d), got %d", StepMessage, model.step)
+	}
+	if model.list.Title != typeSelectTitle {
+		t.Errorf("Expected the type selection title to be restored, got %q", model.list.Title)
+	}
+	if header := model.buildHeader(); header != "chore(deps): bump lib" {
+		t.Errorf("Unexpected header %q", header)
+	}
+	if body := model.body.Value(); body != "lib:\n- feat: add a" {
+		t.Errorf("Unexpected body %q", body)
+	}
+
+	newModel, _ = model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	finishCommit(t, newModel.(Model))
+
+	log, _ := exec.Command("git", "log", "-1", "--format=%B").Output()
+	if !strings.HasPrefix(string(log), "chore(deps): bump lib\n\nlib:\n- feat: add a") {
+		t.Errorf("Unexpected commit message %q", string(log))
+	}
+	log, _ = exec.Command("git", "-C", "lib", "log", "-1", "--format=%s").Output()
+	if string(log) != "feat: add a\n" {
+		t.Errorf("Unexpected submodule commit %q", string(log))
+	}
+}
+
+func TestSubmodulesSkipped(t *testing.T) {
+	setupSubmoduleRepo(t)
+
+	model := NewModel(Options{Submodules: true})
+	newModel, _ := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}})
+	model = newModel.(Model)
+	if model.step != StepTypeSelect || model.submodules != nil {
+		t.Errorf("Expected the normal flow, got step %d", model.step)
+	}
+}
+
+func TestSubmoduleRestageAndRetry(t *testing.T) {
+	setupSubmoduleRepo(t)
+	hooks, err := exec.Command("git", "-C", "lib", "rev-parse", "--path-format=absolute", "--git-path", "hooks").Output()
+	if err != nil {
+		t.Fatalf("Failed to find the hooks of lib: %v", err)
+	}
+	hook := filepath.Join(strings.TrimSpace(string(hooks)), "pre-commit")
+	if err := os.WriteFile(hook, []byte(formatterHook), 0755); err != nil {
+		t.Fatalf("Failed to create hook: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join("lib", "a.txt"), []byte("unformatted\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	runGit(t, "-C", "lib", "add", "a.txt")
+
+	model := NewModel(Options{Submodules: true})
+	newModel, _ := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}})
+	model = commitSplitBucket(t, newModel.(Model), "add a")
+	if model.step != StepError || !model.isHookFailure() {
+		t.Fatalf("Expected a hook failure, got step %d and result %+v", model.step, model.gitResult)
+	}
+
+	// The changes of the hook are found and staged in the submodule
+	newModel, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'a'}})
+	model = newModel.(Model)
+	if model.recoveryNote != "" {
+		t.Fatalf("Expected the changes of the hook to be staged, got %q", model.recoveryNote)
+	}
+	model = finishCommit(t, model)
+	if model.gitResult == nil || !model.gitResult.Success {
+		t.Fatalf("Expected the retry to succeed, got %+v", model.gitResult)
+	}
+	content, _ := exec.Command("git", "-C", "lib", "show", "HEAD:a.txt").Output()
+	if string(content) != "formatted\n" {
+		t.Errorf("Expected the formatted file in the submodule commit, got %q", content)
+	}
+}
@@ -0,0 +1,131 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/denysvitali/git-cc/pkg/git"
+)
+
+// maxSummaryFiles is the number of changed files listed in the summary.
+const maxSummaryFiles = 10
+
+// summaryTimeoutMsg closes the summary once Options.SummaryDelay elapsed.
+type summaryTimeoutMsg struct{}
+
+// commitSummary describes the commit just created.
+type commitSummary struct {
+	branch   string
+	files    []git.FileStat
+	upstream *git.UpstreamStatus
+	// err is why part of the summary could not be read.
+	err error
+}
+
+// showSummary switches to the summary of the commit just created. It closes on
+// a key press, or once Options.SummaryDelay elapsed if it is set.
+func (m Model) showSummary() (tea.Model, tea.Cmd) {
+	summary := &commitSummary{}
+	var errs []error
+	var err error
+	if summary.branch, err = git.CurrentBranch(); err != nil {
+		errs = append(errs, err)
+	}
+	if summary.files, err = git.CommitStats(m.gitResult.SHA); err != nil {
+		errs = append(errs, err)
+	}
+	if summary.upstream, err = git.Upstream(); err != nil {
+		errs = append(errs, err)
+	}
+	if len(errs) > 0 {
+		summary.err = errs[0]
+	}
+
+	m.summary = summary
+	m.step = StepSummary
+	if m.opts.SummaryDelay > 0 {
+		return m, tea.Tick(m.opts.SummaryDelay, func(time.Time) tea.Msg { return summaryTimeoutMsg{} })
+	}
+	return m, nil
+}
+
+// fileTotals returns the number of inserted and deleted lines in files.
+func fileTotals(files []git.FileStat) (insertions, deletions int) {
+	for _, file := range files {
+		insertions += file.Insertions
+		deletions += file.Deletions
+	}
+	return insertions, deletions
+}
+
+// renderFileStats lists the changed files with their insertions and
+// deletions, up to limit of them, followed by the totals.
+func renderFileStats(files []git.FileStat, limit int) string {
+	var b strings.Builder
+	width := 0
+	for i, file := range files {
+		if i < limit {
+			width = max(width, len(file.Path))
+		}
+	}
+	for i, file := range files {
+		if i == limit {
+			b.WriteString(fmt.Sprintf(" … and %d more\n", len(files)-limit))
+			break
+		}
+		b.WriteString(fmt.Sprintf(" %-*s  ", width, file.Path))
+		if file.Binary {
+			b.WriteString("binary\n")
+			continue
+		}
+		b.WriteString(diffAddStyle.Render(fmt.Sprintf("+%d", file.Insertions)) + " " +
+			diffDelStyle.Render(fmt.Sprintf("-%d", file.Deletions)) + "\n")
+	}
+	insertions, deletions := fileTotals(files)
+	b.WriteString(fmt.Sprintf("%s changed, %s(+), %s(-)\n",
+		plural(len(files), "file"), plural(insertions, "insertion"), plural(deletions, "deletion")))
+	return b.String()
+}
+
+func (m Model) summaryView() string {
+	summary := m.summary
+	branch := summary.branch
+	if branch == "" {
+		branch = "detached HEAD"
+	}
+	header, _, _ := strings.Cut(m.commitMsg, "\n")
+
+	var b strings.Builder
+	b.WriteString(titleStyle.Render(fmt.Sprintf("Committed %s on %s", git.ShortSHA(m.gitResult.SHA), branch)) + "\n\n")
+	b.WriteString(header + "\n\n")
+
+	b.WriteString(renderFileStats(summary.files, maxSummaryFiles) + "\n")
+
+	if upstream := summary.upstream; upstream != nil {
+		b.WriteString(fmt.Sprintf("%s is %s ahead and %s behind %s\n\n", branch,
+			plural(upstream.Ahead, "commit"), plural(upstream.Behind, "commit"), upstream.Name))
+	} else if summary.branch != "" {
+		b.WriteString(fmt.Sprintf("%s has no upstream branch\n\n", branch))
+	}
+	if summary.err != nil {
+		b.WriteString(errorStyle.Render(summary.err.Error()) + "\n\n")
+	}
+
+	help := "Press any key to exit"
+	if m.opts.SummaryDelay > 0 {
+		help += fmt.Sprintf(" (closes after %s)", m.opts.SummaryDelay)
+	}
+	b.WriteString(promptStyle.Render(help))
+	return b.String()
+}
+
+// plural returns n followed by noun, adding an "s" unless n is one.
+func plural(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
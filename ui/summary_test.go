@@ -0,0 +1,85 @@
+package ui
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestCommitSummary(t *testing.T) {
+	setupRepo(t, repoOptions{branch: "main", commit: true})
+	if err := exec.Command("git", "branch", "-q", "--track", "base", "main").Run(); err != nil {
+		t.Fatalf("Failed to create branch: %v", err)
+	}
+	if err := exec.Command("git", "branch", "-q", "--set-upstream-to", "base", "main").Run(); err != nil {
+		t.Fatalf("Failed to set upstream: %v", err)
+	}
+
+	model := NewModel(Options{Summary: true})
+	newModel, _ := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model = newModel.(Model)
+	newModel, _ = model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model = newModel.(Model)
+	model.message.SetValue("add a")
+	newModel, _ = model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model = finishCommit(t, newModel.(Model))
+
+	if model.step != StepSummary {
+		t.Fatalf("Expected StepSummary (%d), got %d", StepSummary, model.step)
+	}
+	if model.summary.branch != "main" || len(model.summary.files) != 1 || model.summary.files[0].Insertions != 1 {
+		t.Errorf("Unexpected summary %+v", model.summary)
+	}
+	if upstream := model.summary.upstream; upstream == nil || upstream.Ahead != 1 || upstream.Behind != 0 {
+		t.Errorf("Expected main to be a commit ahead of base, got %+v", upstream)
+	}
+
+	view := model.View()
+	for _, expected := range []string{"on main", "feat: add a", "a.txt", "1 file changed, 1 insertion(+), 0 deletions(-)",
+		"1 commit ahead and 0 commits behind base", "Press any key"} {
+		if !strings.Contains(view, expected) {
+			t.Errorf("Expected %q in the summary, got %q", expected, view)
+		}
+	}
+
+	_, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}})
+	if cmd == nil {
+		t.Fatal("Expected a key press to quit")
+	}
+	if _, ok := cmd().(tea.QuitMsg); !ok {
+		t.Error("Expected a key press to quit")
+	}
+}
+
+func TestCommitSummaryDelay(t *testing.T) {
+	setupRepo(t, repoOptions{})
+
+	model := NewModel(Options{Summary: true, SummaryDelay: time.Millisecond})
+	newModel, _ := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model = newModel.(Model)
+	newModel, _ = model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model = newModel.(Model)
+	model.message.SetValue("add a")
+	newModel, _ = model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model = newModel.(Model)
+
+	result := finishCommit(t, model)
+	if result.step != StepSummary {
+		t.Fatalf("Expected StepSummary (%d), got %d", StepSummary, result.step)
+	}
+	view := result.View()
+	if !strings.Contains(view, "has no upstream branch") || !strings.Contains(view, "closes after 1ms") {
+		t.Errorf("Expected a branch without upstream and the delay, got %q", view)
+	}
+
+	_, cmd := result.Update(summaryTimeoutMsg{})
+	if cmd == nil {
+		t.Fatal("Expected the summary to close after the delay")
+	}
+	if _, ok := cmd().(tea.QuitMsg); !ok {
+		t.Error("Expected the summary to close after the delay")
+	}
+}